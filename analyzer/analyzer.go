@@ -1,9 +1,8 @@
 package analyzer
 
 import (
-	"bytes"
+	"errors"
 	"go/ast"
-	"go/format"
 	"go/token"
 	"go/types"
 	"slices"
@@ -13,70 +12,175 @@ import (
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/helpers"
 )
 
 func New() *analysis.Analyzer {
-	return &analysis.Analyzer{
-		Name:     "SprintfBomb",
-		URL:      "https://github.com/m-ocean-it/go-sprintf-bomb",
-		Doc:      "https://github.com/m-ocean-it/go-sprintf-bomb",
-		Run:      run,
-		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	cfg := &config{printfFuncs: funcMapping{}}
+
+	a := &analysis.Analyzer{
+		Name: "SprintfBomb",
+		URL:  "https://github.com/m-ocean-it/go-sprintf-bomb",
+		Doc:  "https://github.com/m-ocean-it/go-sprintf-bomb",
+		Run: func(pass *analysis.Pass) (any, error) {
+			return run(pass, cfg)
+		},
+		Requires:  []*analysis.Analyzer{inspect.Analyzer},
+		FactTypes: []analysis.Fact{new(printWrapper)},
+	}
+
+	a.Flags.BoolVar(&cfg.wrapErrors, "wrap-errors", false,
+		"rewrite fmt.Errorf calls with %w into an error type added to the package")
+	a.Flags.BoolVar(&cfg.logCalls, "log-calls", false,
+		"rewrite the printf-style functions of log and testing, e.g. log.Printf into log.Print")
+	a.Flags.Var(cfg.printfFuncs, "printf-funcs",
+		"comma-separated printf wrappers to rewrite into their non-format siblings, e.g. example.com/mylog.Infof=Info")
+
+	return a
+}
+
+// config holds the opt-in behavior set with the analyzer flags.
+type config struct {
+	wrapErrors  bool
+	logCalls    bool
+	printfFuncs funcMapping
+}
+
+// funcMapping maps the full name of a printf-like function, as printed by
+// types.Func.FullName, to the name of its non-format sibling.
+type funcMapping map[string]string
+
+func (m funcMapping) String() string {
+	entries := make([]string, 0, len(m))
+	for name, sibling := range m {
+		entries = append(entries, name+"="+sibling)
 	}
+
+	slices.Sort(entries)
+
+	return strings.Join(entries, ",")
+}
+
+func (m funcMapping) Set(value string) error {
+	for entry := range strings.SplitSeq(value, ",") {
+		name, sibling, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" || !token.IsIdentifier(sibling) {
+			return errors.New("want name=Sibling, e.g. example.com/mylog.Infof=Info, got " + strconv.Quote(entry))
+		}
+
+		m[name] = sibling
+	}
+
+	return nil
 }
 
 type filePath = string
 type packagesOutput = map[filePath]*packagesFileResult
 
 type packagesFileResult struct {
-	fmtCount     int
-	addedStrConv bool
+	importRefs   map[string]int  // references to the imports left in the file, by path
+	dropped      map[string]bool // imports whose references were dropped by a fix
+	addedImports []string
+	usedHelpers  []*helpers.Helper
+	temporaries  map[*types.Scope][]string // variables declared by the fixes, by block
+	importNames  map[string]string         // the names the rewritten code imports packages by
+	varUses      map[*types.Var]int        // uses of the local variables, shared by the files of the package
+	rewritten    []ast.Node                // nodes replaced by the fixes
+	src          []byte                    // content of the file, nil if it couldn't be read
 }
 
-func run(pass *analysis.Pass) (any, error) {
+// overlapsRewrite reports whether node overlaps a node replaced by a fix. The
+// calls nested in a rewritten one are copied as they are, while a rewrite of an
+// enclosing node would be conflicting.
+func (r *packagesFileResult) overlapsRewrite(node ast.Node) bool {
+	return slices.ContainsFunc(r.rewritten, func(n ast.Node) bool {
+		return node.Pos() < n.End() && n.Pos() < node.End()
+	})
+}
+
+func (r *packagesFileResult) newDependencies() dependencies {
+	return dependencies{names: r.importNames}
+}
+
+func (r *packagesFileResult) addImport(path string) {
+	if !slices.Contains(r.addedImports, path) {
+		r.addedImports = append(r.addedImports, path)
+	}
+}
+
+func (r *packagesFileResult) addDependencies(deps dependencies) {
+	for _, path := range deps.imports {
+		r.addImport(path)
+	}
+
+	for _, helper := range deps.helpers {
+		r.addHelper(helper)
+	}
+}
+
+func (r *packagesFileResult) addHelper(helper *helpers.Helper) {
+	if slices.Contains(r.usedHelpers, helper) {
+		return
+	}
+
+	r.usedHelpers = append(r.usedHelpers, helper)
+
+	for _, required := range helper.Requires {
+		r.addHelper(required)
+	}
+}
+
+func run(pass *analysis.Pass, cfg *config) (any, error) {
+	findWrappers(pass, cfg)
+
 	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{
 		(*ast.CallExpr)(nil),
 	}
 
 	packagesResult := packagesOutput{}
+	varUses := countVarUses(pass.TypesInfo)
 
-	insp.Preorder(nodeFilter, func(node ast.Node) {
-		diagnostic := processNode(pass.Fset, pass.TypesInfo, node, packagesResult)
-		if diagnostic == nil {
-			return
-		}
+	for _, file := range pass.Files {
+		filename := pass.Fset.Position(file.Pos()).Filename
 
-		pass.Report(*diagnostic)
-	})
+		var src []byte
+		if pass.ReadFile != nil {
+			src, _ = pass.ReadFile(filename)
+		}
 
-	importSpecFilter := []ast.Node{
-		(*ast.GenDecl)(nil),
+		packagesResult[filename] = &packagesFileResult{
+			importRefs:  countImportReferences(pass.TypesInfo, file),
+			importNames: importNames(pass.Pkg, pass.TypesInfo, file),
+			varUses:     varUses,
+			src:         src,
+		}
 	}
 
-	insp.Preorder(importSpecFilter, func(n ast.Node) {
-		genDecl, _ := n.(*ast.GenDecl)
-		if genDecl == nil {
-			return // just in case...
+	for cursor := range insp.Root().Preorder(nodeFilter...) {
+		diagnostic := processNode(pass.Fset, pass.TypesInfo, cfg, cursor, packagesResult)
+		if diagnostic == nil {
+			continue
 		}
 
-		if genDecl.Tok != token.IMPORT {
-			return
-		}
+		pass.Report(*diagnostic)
+	}
 
-		fPath := pass.Fset.Position(genDecl.TokPos).Filename
-		filePkgResult := packagesResult[fPath]
-		if filePkgResult == nil {
-			return
-		}
+	helpersDiagnostic := processHelpers(pass, packagesResult)
+	if helpersDiagnostic != nil {
+		pass.Report(*helpersDiagnostic)
+	}
 
-		importDiagnostic := processImportBlock(pass.Fset, genDecl, filePkgResult)
+	for _, file := range pass.Files {
+		importDiagnostic := processImports(pass.Fset, file, packagesResult[pass.Fset.Position(file.Pos()).Filename])
 		if importDiagnostic == nil {
-			return
+			continue
 		}
 
 		pass.Report(*importDiagnostic)
-	})
+	}
 
 	return nil, nil
 }
@@ -97,9 +201,11 @@ func newAnalysisDiagnostic(
 func processNode(
 	fset *token.FileSet,
 	typesInfo *types.Info,
-	node ast.Node,
+	cfg *config,
+	cursor inspector.Cursor,
 	pkgOut packagesOutput,
 ) *analysis.Diagnostic {
+	node := cursor.Node()
 	if node == nil {
 		return nil
 	}
@@ -116,18 +222,20 @@ func processNode(
 		pkgOut[fPath] = filePkgOut
 	}
 
-	return processExpr(fset, typesInfo, expr, filePkgOut)
+	return processExpr(fset, typesInfo, cfg, cursor, expr, filePkgOut)
 }
 
 func processExpr(
 	fset *token.FileSet,
 	typesInfo *types.Info,
+	cfg *config,
+	cursor inspector.Cursor,
 	expr ast.Expr,
 	filePkgOut *packagesFileResult,
 ) *analysis.Diagnostic {
 	switch e := expr.(type) {
 	case *ast.CallExpr:
-		return processCallExpr(fset, typesInfo, e, filePkgOut)
+		return processCallExpr(fset, typesInfo, cfg, cursor, e, filePkgOut)
 	default:
 		return nil
 	}
@@ -136,193 +244,322 @@ func processExpr(
 func processCallExpr(
 	fset *token.FileSet,
 	typesInfo *types.Info,
+	cfg *config,
+	cursor inspector.Cursor,
 	callExpr *ast.CallExpr,
 	filePkgOut *packagesFileResult,
 ) *analysis.Diagnostic {
-	selExpr, _ := callExpr.Fun.(*ast.SelectorExpr)
-	if selExpr == nil {
+	if filePkgOut.overlapsRewrite(callExpr) {
 		return nil
 	}
 
-	xIdent, _ := selExpr.X.(*ast.Ident)
-	if xIdent == nil {
-		return nil
+	if rewrite, ok := ProcessLogCall(typesInfo, cfg, cursor, filePkgOut); ok {
+		return newRewriteDiagnostic(fset, typesInfo, cursor, rewrite, filePkgOut)
 	}
-	if xIdent.Name != "fmt" {
+
+	// The package may be imported under another name, or with a dot.
+	fn, _ := typeutil.Callee(typesInfo, callExpr).(*types.Func)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "fmt" || fn.Signature().Recv() != nil {
 		return nil
 	}
 
-	filePkgOut.fmtCount++
+	var analyze callAnalyzer
 
-	if selExpr.Sel.Name != "Sprintf" {
-		return nil
-	}
+	switch fn.Name() {
+	case "Sprintf":
+		if len(callExpr.Args) < 2 {
+			// TODO: handle case
+			return nil
+		}
+
+		if rewrite, ok := ProcessSprintfDestination(typesInfo, cursor, filePkgOut); ok {
+			return newRewriteDiagnostic(fset, typesInfo, cursor, rewrite, filePkgOut)
+		}
+
+		analyze = analyzeSprintfCall
+	case "Errorf":
+		if len(callExpr.Args) < 1 {
+			return nil
+		}
 
-	if len(callExpr.Args) < 2 {
-		// TODO: handle case
+		analyze = analyzeErrorfCall
+		if cfg.wrapErrors {
+			analyze = analyzeWrappingErrorfCall
+		}
+	case "Sprint":
+		analyze = analyzeSprintCall
+	case "Sprintln":
+		analyze = analyzeSprintlnCall
+	case "Fprintf":
+		rewrite, ok := ProcessFprintfCall(typesInfo, cursor, filePkgOut)
+		if !ok {
+			return nil
+		}
+
+		return newRewriteDiagnostic(fset, typesInfo, cursor, rewrite, filePkgOut)
+	default:
 		return nil
 	}
 
-	return optimizeSprintf(fset, typesInfo, callExpr, filePkgOut)
+	return optimizeSprintf(fset, typesInfo, cursor, callExpr, analyze, filePkgOut)
 }
 
 func optimizeSprintf(
 	fset *token.FileSet,
 	typesInfo *types.Info,
+	cursor inspector.Cursor,
 	callExpr *ast.CallExpr,
+	analyze callAnalyzer,
 	filePkgOut *packagesFileResult,
 ) *analysis.Diagnostic {
-	newExpr, ok := ProcessSprintfCall(typesInfo, callExpr, filePkgOut)
+	rewrite, ok := ProcessSprintfCall(typesInfo, cursor, analyze, filePkgOut)
 	if !ok {
 		return nil
 	}
 
-	return newAnalysisDiagnostic(
-		callExpr,
-		"Sprintf could be optimized away",
-		[]analysis.SuggestedFix{
-			{
-				Message: "Sprintf could be optimized away",
-				TextEdits: []analysis.TextEdit{
-					{
-						Pos:     callExpr.Pos(),
-						End:     callExpr.End(),
-						NewText: []byte(formatNode(fset, newExpr)),
-					},
-				},
-			},
-		},
-	)
+	filePkgOut.importRefs["fmt"]--
+
+	return newRewriteDiagnostic(fset, typesInfo, cursor, rewrite, filePkgOut)
 }
 
-func processImportBlock(
+func newRewriteDiagnostic(
 	fset *token.FileSet,
-	genDecl *ast.GenDecl,
-	filePkgResult *packagesFileResult,
+	typesInfo *types.Info,
+	cursor inspector.Cursor,
+	rewrite sprintfRewrite,
+	filePkgOut *packagesFileResult,
 ) *analysis.Diagnostic {
-	if filePkgResult.fmtCount > 0 && !filePkgResult.addedStrConv {
-		return nil
+	callExpr := cursor.Node().(*ast.CallExpr)
+
+	replaced := cursor
+	if rewrite.target != nil {
+		for cur := range cursor.Enclosing() {
+			if cur.Node() == rewrite.target {
+				replaced = cur
+				break
+			}
+		}
 	}
 
-	var alreadyHasStrConv bool
-	fmtImportIndex := -1
+	filePkgOut.rewritten = append(filePkgOut.rewritten, replaced.Node())
 
-	for i, spec := range genDecl.Specs {
-		importSpec, _ := spec.(*ast.ImportSpec)
-		if importSpec == nil {
-			continue // just in case...
-		}
+	var textEdits []analysis.TextEdit
 
-		pathLit := importSpec.Path
-		if pathLit.Kind != token.STRING {
-			return nil
-		}
+	if len(rewrite.temporaries) > 0 {
+		stmtPos := rewrite.stmt.Pos()
 
-		importPath, err := strconv.Unquote(pathLit.Value)
-		if err != nil {
-			return nil
+		var newText strings.Builder
+		for _, temporary := range rewrite.temporaries {
+			p := newPrinter(fset, typesInfo, filePkgOut.src, stmtPos)
+			newText.WriteString(p.print(temporary, token.LowestPrec))
+			newText.WriteString("\n")
+			newText.WriteString(p.indent)
 		}
 
-		switch importPath {
-		case "fmt":
-			fmtImportIndex = i
-		case "strconv":
-			alreadyHasStrConv = true
-		}
+		textEdits = append(textEdits, analysis.TextEdit{
+			Pos:     stmtPos,
+			End:     stmtPos,
+			NewText: []byte(newText.String()),
+		})
 	}
 
-	initialGenDeclEnd := genDecl.End()
+	pos := replaced.Node().Pos()
 
-	if filePkgResult.fmtCount == 0 && fmtImportIndex > -1 {
-		genDecl.Specs = slices.Delete(genDecl.Specs, fmtImportIndex, fmtImportIndex+1)
-	}
-	if filePkgResult.addedStrConv && !alreadyHasStrConv {
-		genDecl.Specs = append(genDecl.Specs, &ast.ImportSpec{
-			Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("strconv")},
-		})
+	var newText string
+
+	if rewrite.writes != nil {
+		// The call is a statement of its own, every write goes on its own line.
+		writes := make([]string, 0, len(rewrite.writes))
+
+		var indent string
+		for _, write := range rewrite.writes {
+			p := newPrinter(fset, typesInfo, filePkgOut.src, pos)
+			writes = append(writes, p.print(write, token.LowestPrec))
+			indent = p.indent
+		}
+
+		newText = strings.Join(writes, "\n"+indent)
+	} else {
+		newText = newPrinter(fset, typesInfo, filePkgOut.src, pos).
+			print(rewrite.expr, contextPrecedence(typesInfo, replaced))
 	}
 
+	textEdits = append(textEdits, analysis.TextEdit{
+		Pos:     pos,
+		End:     replaced.Node().End(),
+		NewText: []byte(newText),
+	})
+
+	message := calleeName(callExpr) + " could be optimized away"
+
 	return newAnalysisDiagnostic(
-		genDecl,
-		"Fix imports",
+		callExpr,
+		message,
 		[]analysis.SuggestedFix{
 			{
-				Message: "Fix imports",
-				TextEdits: []analysis.TextEdit{
-					{
-						Pos:     genDecl.Pos(),
-						End:     initialGenDeclEnd,
-						NewText: []byte(formatNode(fset, genDecl)),
-					},
-				},
+				Message:   message,
+				TextEdits: textEdits,
 			},
 		},
 	)
 }
 
-var supportedVerbs = []string{"%s", "%d", "%f"} // TODO support more
+// countImportReferences counts the references to every import of file: the
+// package names of qualified identifiers, or the names brought in by a
+// dot-import. Each rewritten call takes its fmt reference away, along with the
+// ones of a constant format it replaces with literals.
+func countImportReferences(typesInfo *types.Info, file *ast.File) map[string]int {
+	refs := map[string]int{}
 
-func isVerb(rs string) bool {
-	return slices.Contains(supportedVerbs, rs)
+	forEachImportReference(typesInfo, file, func(path string) {
+		refs[path]++
+	})
+
+	return refs
 }
 
-func formatNode(fset *token.FileSet, node ast.Node) string {
-	switch n := node.(type) {
-	case *ast.BinaryExpr:
-		return formatBinaryExpr(fset, n)
-	default:
-		return formatAnyNode(fset, n)
+// dropReferences takes the references to imports in the node out of the count,
+// as a fix removes the node.
+func (r *packagesFileResult) dropReferences(typesInfo *types.Info, node ast.Node) {
+	if node == nil {
+		return
 	}
+
+	forEachImportReference(typesInfo, node, func(path string) {
+		r.importRefs[path]--
+
+		if r.dropped == nil {
+			r.dropped = map[string]bool{}
+		}
+		r.dropped[path] = true
+	})
 }
 
-func formatAnyNode(fset *token.FileSet, node ast.Node) string {
-	buf := new(bytes.Buffer)
-	if err := format.Node(buf, fset, node); err != nil {
-		return ""
+// dropOperand takes an operand removed by a fix out of the counts, so that a
+// variable is only dropped while it is used elsewhere.
+func (r *packagesFileResult) dropOperand(typesInfo *types.Info, operand ast.Expr) {
+	r.dropReferences(typesInfo, operand)
+
+	if r.varUses == nil {
+		return
 	}
 
-	return buf.String()
+	ast.Inspect(operand, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			if v, ok := typesInfo.Uses[ident].(*types.Var); ok && v.Kind() == types.LocalVar {
+				r.varUses[v]--
+			}
+		}
+
+		return true
+	})
 }
 
-func formatBinaryExpr(fset *token.FileSet, node ast.Node) string {
-	binExpr := node.(*ast.BinaryExpr)
+func forEachImportReference(typesInfo *types.Info, node ast.Node, visit func(path string)) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.SelectorExpr:
+			ident, ok := n.X.(*ast.Ident)
+			if !ok {
+				break
+			}
 
-	stringBuilder := &strings.Builder{}
+			if pkgName, ok := typesInfo.Uses[ident].(*types.PkgName); ok {
+				visit(pkgName.Imported().Path())
 
-	stack := []*ast.BinaryExpr{binExpr}
-	var popped bool
+				return false // n.Sel is qualified
+			}
+		case *ast.Ident:
+			// Declared in the package scope of another package through a dot-import,
+			// or in the package itself, which is never imported.
+			obj := typesInfo.Uses[n]
+			if obj != nil && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+				visit(obj.Pkg().Path())
+			}
+		}
 
-	for len(stack) > 0 {
-		lastBinExpr := stack[len(stack)-1]
+		return true
+	})
+}
 
-		if lastBinExpr.Op != token.ADD {
-			panic("wrong op")
+func calleeName(callExpr *ast.CallExpr) string {
+	switch fun := ast.Unparen(callExpr.Fun).(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	default:
+		return "call"
+	}
+}
+
+// processHelpers appends the helpers used by the rewritten calls to the first
+// file that uses any of them, unless the package already declares them.
+func processHelpers(pass *analysis.Pass, pkgOut packagesOutput) *analysis.Diagnostic {
+	var (
+		hostFile   *ast.File
+		hostResult *packagesFileResult
+		used       []*helpers.Helper
+	)
+
+	for _, file := range pass.Files {
+		fileResult := pkgOut[pass.Fset.Position(file.Pos()).Filename]
+		if fileResult == nil {
+			continue
 		}
 
-		if popped {
-			stringBuilder.WriteString(" + ")
+		for _, helper := range fileResult.usedHelpers {
+			if pass.Pkg.Scope().Lookup(helper.Name) != nil {
+				continue // added by an earlier run
+			}
 
-			if err := format.Node(stringBuilder, fset, lastBinExpr.Y); err != nil {
-				return ""
+			if hostFile == nil {
+				hostFile, hostResult = file, fileResult
+			}
+			if !slices.Contains(used, helper) {
+				used = append(used, helper)
 			}
-			stack = stack[:len(stack)-1] // pop
-			popped = true
+		}
+	}
+
+	if hostFile == nil {
+		return nil
+	}
 
+	var newText strings.Builder
+
+	for _, helper := range helpers.All {
+		if !slices.Contains(used, helper) {
 			continue
 		}
 
-		switch x := lastBinExpr.X.(type) {
-		case *ast.BinaryExpr:
-			stack = append(stack, x)
-			popped = false
-			continue
-		default:
-			if err := format.Node(stringBuilder, fset, x); err != nil {
-				return ""
-			}
-			popped = true
+		newText.WriteString("\n")
+		newText.WriteString(qualifyImports(helper.Source(), hostResult.importNames))
+
+		for _, path := range helper.Imports {
+			hostResult.addImport(path)
 		}
 	}
 
-	return stringBuilder.String()
+	pos := hostFile.FileEnd
+
+	return &analysis.Diagnostic{
+		Pos:     pos,
+		End:     pos,
+		Message: "Add helpers",
+		SuggestedFixes: []analysis.SuggestedFix{
+			{
+				Message: "Add helpers",
+				TextEdits: []analysis.TextEdit{
+					{
+						Pos:     pos,
+						End:     pos,
+						NewText: []byte(newText.String()),
+					},
+				},
+			},
+		},
+	}
 }
@@ -16,4 +16,216 @@ func TestAnalyzer(t *testing.T) {
 
 		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "default")
 	})
+
+	t.Run("v_verb", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "v_verb")
+	})
+
+	t.Run("bools", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "bools")
+	})
+
+	t.Run("floats", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "floats")
+	})
+
+	t.Run("integers", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "integers")
+	})
+
+	t.Run("padding", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "padding")
+	})
+
+	t.Run("quoting", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "quoting")
+	})
+
+	t.Run("chars", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "chars")
+	})
+
+	t.Run("hexbytes", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "hexbytes")
+	})
+
+	t.Run("reordered", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "reordered")
+	})
+
+	t.Run("formatters", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "formatters")
+	})
+
+	t.Run("gosyntax", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "gosyntax")
+	})
+
+	t.Run("sprint", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "sprint")
+	})
+
+	t.Run("errorf", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "errorf")
+	})
+
+	t.Run("wrap_errors", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+		if err := a.Flags.Set("wrap-errors", "true"); err != nil {
+			t.Fatal(err)
+		}
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "wrap_errors")
+	})
+
+	t.Run("fprintf", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "fprintf")
+	})
+
+	t.Run("destinations", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "destinations")
+	})
+
+	t.Run("log_calls", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+		if err := a.Flags.Set("log-calls", "true"); err != nil {
+			t.Fatal(err)
+		}
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "log_calls")
+	})
+
+	t.Run("printf_funcs", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+		if err := a.Flags.Set("printf-funcs", "mylog.Infof=Info,(*mylog.Logger).Errorf=Error"); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Flags.Set("printf-funcs", "mylog.Wrapf=Wrap,printf_funcs.debugf=debug"); err != nil {
+			t.Fatal(err)
+		}
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "mylog", "printf_funcs")
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "resolve")
+	})
+
+	t.Run("imports", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "imports")
+	})
+
+	t.Run("printing", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "printing")
+	})
+
+	t.Run("raw_strings", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "raw_strings")
+	})
+
+	t.Run("const_formats", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "const_formats")
+	})
+
+	t.Run("constants", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "constants")
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		t.Parallel()
+
+		a := New()
+
+		analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "nested")
+	})
 }
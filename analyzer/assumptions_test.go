@@ -2,6 +2,8 @@ package analyzer
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"testing"
 )
 
@@ -57,6 +59,177 @@ func TestInternalStringValueIsUsed(t *testing.T) {
 	}
 }
 
+func TestPrecedenceWithVVerb(t *testing.T) {
+	t.Parallel()
+
+	for _, verb := range []string{"%v", "%+v"} {
+		if got := fmt.Sprintf(verb, stringerError("internal value")); got != "Error()" {
+			t.Fatalf("%s: got: %s, expected: Error()", verb, got)
+		}
+
+		if got := fmt.Sprintf(verb, stringStringer("internal value")); got != "String()" {
+			t.Fatalf("%s: got: %s, expected: String()", verb, got)
+		}
+
+		if got := fmt.Sprintf(verb, wrappedString("internal value")); got != "internal value" {
+			t.Fatalf("%s: got: %s, expected: internal value", verb, got)
+		}
+	}
+}
+
+func TestFloatVVerbIsShortestG(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []float64{0, 3.14, -2.5, 1e6, 1e21, 1e-5, 123456789.125} {
+		got := fmt.Sprintf("%v", f)
+
+		expected := strconv.FormatFloat(f, 'g', -1, 64)
+		if got != expected {
+			t.Fatalf("got: %s, expected: %s", got, expected)
+		}
+	}
+}
+
+func TestTVerbIgnoresStringer(t *testing.T) {
+	t.Parallel()
+
+	got := fmt.Sprintf("val: %t", stringerBool(true))
+
+	expected := "val: true"
+	if got != expected {
+		t.Fatalf("got: %s, expected: %s", got, expected)
+	}
+}
+
+func TestFloatVerbsMatchFormatFloat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		verb string
+		fmt  byte
+		prec int
+	}{
+		{"%e", 'e', 6},
+		{"%E", 'E', 6},
+		{"%f", 'f', 6},
+		{"%F", 'f', 6},
+		{"%g", 'g', -1},
+		{"%G", 'G', -1},
+		{"%x", 'x', -1},
+		{"%X", 'X', -1},
+		{"%b", 'b', -1},
+		{"%v", 'g', -1},
+		{"%.2f", 'f', 2},
+		{"%.f", 'f', 0},
+		{"%.3v", 'g', 3},
+	}
+
+	values := []float64{0, 3.14, -2.5, 1e21, 1e-7, math.Inf(1), math.Inf(-1), math.NaN()}
+
+	for _, tt := range tests {
+		for _, f := range values {
+			got := fmt.Sprintf(tt.verb, f)
+			expected := strconv.FormatFloat(f, tt.fmt, tt.prec, 64)
+			if got != expected {
+				t.Fatalf("%s: got: %s, expected: %s", tt.verb, got, expected)
+			}
+
+			f32 := float32(f)
+			got = fmt.Sprintf(tt.verb, f32)
+			expected = strconv.FormatFloat(float64(f32), tt.fmt, tt.prec, 32)
+			if got != expected {
+				t.Fatalf("%s (float32): got: %s, expected: %s", tt.verb, got, expected)
+			}
+		}
+	}
+}
+
+func TestPrecedenceFormatterOverEverything(t *testing.T) {
+	t.Parallel()
+
+	f := formatterStringerError("internal value")
+
+	for _, verb := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x", "%d", "%t", "%c", "%10s"} {
+		got := fmt.Sprintf(verb, f)
+
+		expected := "Format(" + verb[len(verb)-1:] + ")"
+		if got != expected {
+			t.Fatalf("%s: got: %s, expected: %s", verb, got, expected)
+		}
+	}
+}
+
+func TestPrecedenceGoStringerWithSharpVVerb(t *testing.T) {
+	t.Parallel()
+
+	g := goStringerError("internal value")
+
+	if got := fmt.Sprintf("%#v", g); got != "GoString()" {
+		t.Fatalf("%%#v: got: %s, expected: GoString()", got)
+	}
+
+	if got := fmt.Sprintf("%#12v|", g); got != "  GoString()|" {
+		t.Fatalf("%%#12v: got: %s, expected: '  GoString()|'", got)
+	}
+
+	// GoString() is used only with %#v.
+	for _, verb := range []string{"%v", "%+v", "%s"} {
+		if got := fmt.Sprintf(verb, g); got != "Error()" {
+			t.Fatalf("%s: got: %s, expected: Error()", verb, got)
+		}
+	}
+}
+
+func TestSharpVVerbOfBasicTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format   string
+		arg      any
+		expected string
+	}{
+		{format: "%#v", arg: "a\tb", expected: strconv.Quote("a\tb")},
+		{format: "%#v", arg: uint8(42), expected: "0x2a"},
+		{format: "%#v", arg: uint(0), expected: "0x0"},
+		{format: "%#v", arg: -42, expected: "-42"},
+		{format: "%#v", arg: 2.5, expected: fmt.Sprintf("%v", 2.5)},
+		{format: "%#v", arg: 1e21, expected: fmt.Sprintf("%v", 1e21)},
+		{format: "%#v", arg: stringStringer("x"), expected: `"x"`},
+		{format: "%#v", arg: stringerBool(true), expected: "true"},
+		{format: "%#08v", arg: uint(42), expected: "0x0000002a"},
+		{format: "%#v", arg: formatterStringerError(""), expected: "Format(v)"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, tt.arg); got != tt.expected {
+			t.Fatalf("%s of %T: got: %s, expected: %s", tt.format, tt.arg, got, tt.expected)
+		}
+	}
+}
+
+func TestTVerbUsesPackageName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format   string
+		arg      any
+		expected string
+	}{
+		{format: "%T", arg: byte(1), expected: "uint8"},
+		{format: "%T", arg: 'a', expected: "int32"},
+		{format: "%T", arg: &strconv.NumError{}, expected: "*strconv.NumError"},
+		{format: "%T", arg: map[string][]stringError{}, expected: "map[string][]analyzer.stringError"},
+		{format: "%T", arg: formatterStringerError(""), expected: "analyzer.formatterStringerError"},
+		{format: "%T", arg: func(...int) (int, error) { return 0, nil }, expected: "func(...int) (int, error)"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, tt.arg); got != tt.expected {
+			t.Fatalf("%s of %T: got: %s, expected: %s", tt.format, tt.arg, got, tt.expected)
+		}
+	}
+}
+
 type stringStringer string
 
 func (s stringStringer) String() string {
@@ -79,3 +252,33 @@ func (s stringError) Error() string {
 }
 
 type wrappedString string
+
+type stringerBool bool
+
+func (s stringerBool) String() string {
+	return "String()"
+}
+
+type formatterStringerError string
+
+func (f formatterStringerError) Format(s fmt.State, verb rune) {
+	_, _ = fmt.Fprintf(s, "Format(%c)", verb)
+}
+func (f formatterStringerError) GoString() string {
+	return "GoString()"
+}
+func (f formatterStringerError) String() string {
+	return "String()"
+}
+func (f formatterStringerError) Error() string {
+	return "Error()"
+}
+
+type goStringerError string
+
+func (g goStringerError) GoString() string {
+	return "GoString()"
+}
+func (g goStringerError) Error() string {
+	return "Error()"
+}
@@ -20,7 +20,7 @@ func BenchmarkOptimization(b *testing.B) {
 
 	b.Run("Concat", func(b *testing.B) {
 		for b.Loop() {
-			_ = name + " is " + strconv.Itoa(age) + " years old. Pi is " + strconv.FormatFloat(pi, 'f', -1, 64) + ". And some error: " + moreText
+			_ = name + " is " + strconv.Itoa(age) + " years old. Pi is " + strconv.FormatFloat(pi, 'f', 6, 64) + ". And some error: " + moreText
 		}
 	})
 }
@@ -0,0 +1,211 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/fmtparse"
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/transform"
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/knowledge"
+)
+
+// foldConstants prints the constant operands at analysis time. fmt itself
+// formats them, given a value of the type the operand has in the call.
+func (a analyzedSprintfCall) foldConstants(typesInfo *types.Info) {
+	for i, arg := range a.args {
+		if _, ok := arg.transformation.(transform.Literal); ok {
+			continue // already known
+		}
+
+		text, ok := foldConstant(typesInfo, arg)
+		if !ok {
+			continue
+		}
+
+		a.args[i].folded = true
+		a.args[i].text = text
+	}
+}
+
+func foldConstant(typesInfo *types.Info, arg sprintfArg) (string, bool) {
+	directive := arg.directive
+	if directive.Verb == 'w' || directive.Prec.FromArg {
+		return "", false
+	}
+
+	value, ok := constantValue(typesInfo, arg.value)
+	if !ok {
+		return "", false
+	}
+
+	values := []any{value}
+
+	if directive.Width.FromArg {
+		width, ok := constantValue(typesInfo, arg.width)
+		if !ok {
+			return "", false
+		}
+
+		values = []any{width, value}
+	}
+
+	return fmt.Sprintf(directiveText(directive), values...), true
+}
+
+// constantValue converts the value of a constant expression to the Go type fmt
+// would get at runtime. The types with methods fmt calls are left out.
+func constantValue(typesInfo *types.Info, expr ast.Expr) (any, bool) {
+	tv, ok := typesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Type == nil {
+		return nil, false
+	}
+
+	t := types.Default(tv.Type)
+
+	if implementsStringMethods(t) ||
+		types.Implements(t, knowledge.Interfaces["fmt.GoStringer"]) ||
+		knowledge.ImplementsFormatter(t) {
+		return nil, false
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return nil, false
+	}
+
+	v := tv.Value
+
+	switch basic.Kind() {
+	case types.Bool:
+		return constant.BoolVal(v), true
+	case types.String:
+		return constant.StringVal(v), true
+	case types.Float32:
+		f, _ := constant.Float32Val(v)
+		return f, true
+	case types.Float64:
+		f, _ := constant.Float64Val(v)
+		return f, true
+	}
+
+	if basic.Info()&types.IsUnsigned != 0 {
+		u, exact := constant.Uint64Val(constant.ToInt(v))
+		if !exact {
+			return nil, false
+		}
+
+		switch basic.Kind() {
+		case types.Uint:
+			return uint(u), true
+		case types.Uint8:
+			return uint8(u), true
+		case types.Uint16:
+			return uint16(u), true
+		case types.Uint32:
+			return uint32(u), true
+		case types.Uint64:
+			return u, true
+		case types.Uintptr:
+			return uintptr(u), true
+		}
+	}
+
+	if basic.Info()&types.IsInteger != 0 {
+		i, exact := constant.Int64Val(constant.ToInt(v))
+		if !exact {
+			return nil, false
+		}
+
+		switch basic.Kind() {
+		case types.Int:
+			return int(i), true
+		case types.Int8:
+			return int8(i), true
+		case types.Int16:
+			return int16(i), true
+		case types.Int32:
+			return int32(i), true
+		case types.Int64:
+			return i, true
+		}
+	}
+
+	return nil, false
+}
+
+// directiveText writes the directive back without its argument indexes, the
+// operands being passed in order.
+func directiveText(directive fmtparse.Directive) string {
+	var sb strings.Builder
+
+	sb.WriteByte('%')
+
+	for _, flag := range []struct {
+		set  bool
+		char byte
+	}{
+		{directive.Flags.Plus, '+'},
+		{directive.Flags.Minus, '-'},
+		{directive.Flags.Sharp, '#'},
+		{directive.Flags.Space, ' '},
+		{directive.Flags.Zero, '0'},
+	} {
+		if flag.set {
+			sb.WriteByte(flag.char)
+		}
+	}
+
+	switch {
+	case directive.Width.FromArg:
+		sb.WriteByte('*')
+	case directive.Width.Present:
+		sb.WriteString(strconv.Itoa(directive.Width.Value))
+	}
+
+	if directive.Prec.Present {
+		sb.WriteByte('.')
+		sb.WriteString(strconv.Itoa(directive.Prec.Value))
+	}
+
+	sb.WriteRune(directive.Verb)
+
+	return sb.String()
+}
+
+// droppedOperands returns the indexes of the operands the rewritten code no
+// longer refers to: the ones printed at analysis time only, or not at all.
+func (a analyzedSprintfCall) droppedOperands() []int {
+	kept := make([]bool, len(a.operands))
+
+	for _, arg := range a.args {
+		if arg.folded {
+			continue
+		}
+
+		if _, ok := arg.transformation.(transform.Literal); !ok {
+			kept[arg.directive.ArgIndex] = true
+		}
+
+		if arg.directive.Width.FromArg {
+			kept[arg.directive.Width.ArgIndex] = true
+		}
+	}
+
+	for _, w := range a.wrapped {
+		kept[w.index] = true
+	}
+
+	var dropped []int
+
+	for i := range a.operands {
+		if !kept[i] {
+			dropped = append(dropped, i)
+		}
+	}
+
+	return dropped
+}
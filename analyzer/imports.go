@@ -0,0 +1,563 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// importablePaths are the packages the rewritten code and the helpers may import.
+var importablePaths = []string{"encoding/hex", "errors", "strconv", "strings", "unicode/utf8"}
+
+// importNames picks the name the rewritten code refers to each importable package
+// by in file: the name of an existing import of the package, or else the package
+// name, numbered while another import or a package-level declaration already
+// uses it.
+func importNames(pkg *types.Package, typesInfo *types.Info, file *ast.File) map[string]string {
+	names := map[string]string{}
+
+	for _, spec := range file.Imports {
+		pkgName := typesInfo.PkgNameOf(spec)
+		if pkgName == nil || pkgName.Name() == "_" || pkgName.Name() == "." {
+			continue
+		}
+
+		path := pkgName.Imported().Path()
+		if _, ok := names[path]; !ok && slices.Contains(importablePaths, path) {
+			names[path] = pkgName.Name()
+		}
+	}
+
+	fileScope := typesInfo.Scopes[file]
+
+	isTaken := func(name string) bool {
+		if pkg.Scope().Lookup(name) != nil {
+			return true
+		}
+
+		if fileScope != nil && fileScope.Lookup(name) != nil {
+			return true
+		}
+
+		return slices.Contains(slices.Collect(maps.Values(names)), name)
+	}
+
+	for _, path := range importablePaths {
+		if _, ok := names[path]; ok {
+			continue
+		}
+
+		names[path] = freeName(defaultImportName(path), isTaken)
+	}
+
+	return names
+}
+
+func defaultImportName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// importsVisible reports whether the names the rewritten code uses for its
+// imports refer to those packages at cursor, and not to a local declaration
+// shadowing them.
+func importsVisible(typesInfo *types.Info, cursor inspector.Cursor, deps dependencies) bool {
+	if len(deps.imports) == 0 {
+		return true
+	}
+
+	scope := enclosingScope(typesInfo, cursor)
+	if scope == nil {
+		return false
+	}
+
+	for _, path := range deps.imports {
+		_, obj := scope.LookupParent(deps.name(path), cursor.Node().Pos())
+
+		switch obj := obj.(type) {
+		case nil:
+			// Imported by the fix.
+		case *types.PkgName:
+			if obj.Imported().Path() != path {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// qualifyImports renames the package qualifiers of a helper source to the names
+// the packages are imported by in the file it is added to.
+func qualifyImports(source string, names map[string]string) string {
+	renames := map[string]string{}
+
+	for path, name := range names {
+		if defaultName := defaultImportName(path); name != defaultName {
+			renames[defaultName] = name
+		}
+	}
+
+	if len(renames) == 0 {
+		return source
+	}
+
+	var (
+		s       scanner.Scanner
+		fset    = token.NewFileSet()
+		file    = fset.AddFile("", -1, len(source))
+		res     strings.Builder
+		last    int
+		pending = -1 // offset of an identifier to rename if a dot follows
+		ident   string
+	)
+
+	s.Init(file, []byte(source), nil, 0)
+
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		if tok == token.PERIOD && pending >= 0 {
+			res.WriteString(source[last:pending])
+			res.WriteString(renames[ident])
+			last = pending + len(ident)
+		}
+
+		pending = -1
+
+		if _, ok := renames[lit]; ok && tok == token.IDENT {
+			pending, ident = file.Offset(pos), lit
+		}
+	}
+
+	res.WriteString(source[last:])
+
+	return res.String()
+}
+
+// processImports removes the imports left unused by the rewrites, fmt and the
+// packages of the constant formats, and adds the imports of the rewritten code
+// to the first import declaration of the file. The edits only touch the lines
+// of the affected imports, so comments and blank-line groups stay as they are;
+// the new imports join the standard library group in sorted order.
+func processImports(
+	fset *token.FileSet,
+	file *ast.File,
+	filePkgResult *packagesFileResult,
+) *analysis.Diagnostic {
+	var (
+		decls    []*ast.GenDecl
+		removed  []*ast.ImportSpec
+		imported = map[string]bool{}
+	)
+
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			decls = append(decls, genDecl)
+		}
+	}
+
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil
+		}
+
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		unused := filePkgResult.importRefs[path] == 0 && (path == "fmt" || filePkgResult.dropped[path])
+
+		if unused && name != "_" {
+			removed = append(removed, spec)
+		}
+
+		if name != "_" && name != "." {
+			imported[path] = true
+		}
+	}
+
+	var added []*ast.ImportSpec
+
+	for _, path := range slices.Sorted(slices.Values(filePkgResult.addedImports)) {
+		if imported[path] {
+			continue
+		}
+
+		spec := &ast.ImportSpec{
+			Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)},
+		}
+
+		if name, ok := filePkgResult.importNames[path]; ok && name != defaultImportName(path) {
+			spec.Name = &ast.Ident{Name: name}
+		}
+
+		added = append(added, spec)
+	}
+
+	if len(removed) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	tokFile := fset.File(file.Pos())
+
+	var (
+		edits    []analysis.TextEdit
+		reported ast.Node
+	)
+
+	for i, decl := range decls {
+		var declRemoved, declAdded []*ast.ImportSpec
+
+		for _, spec := range decl.Specs {
+			if slices.Contains(removed, spec.(*ast.ImportSpec)) {
+				declRemoved = append(declRemoved, spec.(*ast.ImportSpec))
+			}
+		}
+
+		if i == 0 {
+			declAdded = added
+		}
+
+		if len(declRemoved) == 0 && len(declAdded) == 0 {
+			continue
+		}
+
+		if reported == nil {
+			reported = decl
+		}
+
+		edits = append(edits, editImportDecl(tokFile, file, decl, declRemoved, declAdded)...)
+	}
+
+	if len(decls) == 0 {
+		reported = file.Name
+
+		edits = append(edits, analysis.TextEdit{
+			Pos:     file.Name.End(),
+			End:     file.Name.End(),
+			NewText: []byte("\n\n" + importDeclText(added)),
+		})
+	}
+
+	return newAnalysisDiagnostic(
+		reported,
+		"Fix imports",
+		[]analysis.SuggestedFix{
+			{
+				Message:   "Fix imports",
+				TextEdits: edits,
+			},
+		},
+	)
+}
+
+// editImportDecl removes and adds the given imports of decl. A declaration with
+// an unusual layout, e.g. several imports on one line, is printed anew instead.
+func editImportDecl(
+	tokFile *token.File,
+	file *ast.File,
+	decl *ast.GenDecl,
+	removed []*ast.ImportSpec,
+	added []*ast.ImportSpec,
+) []analysis.TextEdit {
+	var kept []*ast.ImportSpec
+
+	for _, spec := range decl.Specs {
+		if !slices.Contains(removed, spec.(*ast.ImportSpec)) {
+			kept = append(kept, spec.(*ast.ImportSpec))
+		}
+	}
+
+	if len(kept) == 0 && len(added) == 0 {
+		start := decl.Pos()
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+
+		return []analysis.TextEdit{deleteLines(tokFile, tokFile.Line(start), tokFile.Line(decl.End()))}
+	}
+
+	lines, ok := newImportLines(tokFile, file, decl)
+	if !ok {
+		return []analysis.TextEdit{{
+			Pos:     decl.Pos(),
+			End:     decl.End(),
+			NewText: []byte(importDeclText(append(kept, added...))),
+		}}
+	}
+
+	var (
+		edits   []analysis.TextEdit
+		inserts = map[token.Pos]string{}
+		groups  = lines.groups(decl)
+		std     []*ast.ImportSpec
+	)
+
+	for _, group := range groups {
+		if slices.ContainsFunc(group, func(spec *ast.ImportSpec) bool { return isStdImport(importPath(spec)) }) {
+			std = group
+			break
+		}
+	}
+
+	if std == nil {
+		// A group of its own, before the others.
+		pos := lineStart(tokFile, lines.start(decl.Specs[0].(*ast.ImportSpec)))
+
+		for _, spec := range added {
+			inserts[pos] += "\t" + importSpecText(spec) + "\n"
+		}
+
+		inserts[pos] += "\n"
+	}
+
+	for _, spec := range added {
+		if std == nil {
+			break
+		}
+
+		pos := lineStart(tokFile, lines.end(std[len(std)-1])+1)
+
+		for _, s := range std {
+			if importPath(s) > importPath(spec) {
+				pos = lineStart(tokFile, lines.start(s))
+				break
+			}
+		}
+
+		inserts[pos] += "\t" + importSpecText(spec) + "\n"
+	}
+
+	for _, group := range groups {
+		for i, spec := range group {
+			if !slices.Contains(removed, spec) {
+				continue
+			}
+
+			start, end := lines.start(spec), lines.end(spec)
+
+			emptied := i == len(group)-1 &&
+				!(len(added) > 0 && slices.Equal(group, std)) &&
+				!slices.ContainsFunc(group, func(s *ast.ImportSpec) bool { return !slices.Contains(removed, s) })
+
+			if emptied {
+				// Drop the blank line separating the group from the next or the
+				// previous one too.
+				switch {
+				case lines.isBlank(end + 1):
+					end++
+				case lines.isBlank(lines.start(group[0]) - 1):
+					blank := lines.start(group[0]) - 1
+					edits = append(edits, deleteLines(tokFile, blank, blank))
+				}
+			}
+
+			edits = append(edits, deleteLines(tokFile, start, end))
+		}
+	}
+
+	for _, pos := range slices.Sorted(maps.Keys(inserts)) {
+		edits = append(edits, analysis.TextEdit{Pos: pos, End: pos, NewText: []byte(inserts[pos])})
+	}
+
+	return edits
+}
+
+// importLines tells which lines of a parenthesized import declaration hold
+// imports or comments, and which ones are blank.
+type importLines struct {
+	tokFile  *token.File
+	lparen   int
+	rparen   int
+	occupied map[int]bool
+}
+
+// newImportLines fails unless every import of decl sits on lines of its own,
+// between the lines of the parentheses.
+func newImportLines(tokFile *token.File, file *ast.File, decl *ast.GenDecl) (importLines, bool) {
+	lines := importLines{
+		tokFile:  tokFile,
+		lparen:   tokFile.Line(decl.Lparen),
+		rparen:   tokFile.Line(decl.Rparen),
+		occupied: map[int]bool{},
+	}
+
+	if !decl.Lparen.IsValid() || len(decl.Specs) == 0 {
+		return lines, false
+	}
+
+	prevEnd := lines.lparen
+
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ImportSpec)
+
+		start, end := lines.start(spec), lines.end(spec)
+		if start <= prevEnd || end >= lines.rparen {
+			return lines, false
+		}
+
+		prevEnd = end
+	}
+
+	for _, group := range file.Comments {
+		if group.Pos() > decl.Lparen && group.End() < decl.Rparen {
+			for line := tokFile.Line(group.Pos()); line <= tokFile.Line(group.End()); line++ {
+				lines.occupied[line] = true
+			}
+		}
+	}
+
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ImportSpec)
+
+		for line := lines.start(spec); line <= lines.end(spec); line++ {
+			lines.occupied[line] = true
+		}
+	}
+
+	return lines, true
+}
+
+// start returns the first line of spec, including its doc comment.
+func (l importLines) start(spec *ast.ImportSpec) int {
+	if spec.Doc != nil {
+		return l.tokFile.Line(spec.Doc.Pos())
+	}
+
+	return l.tokFile.Line(spec.Pos())
+}
+
+// end returns the last line of spec, including its line comment.
+func (l importLines) end(spec *ast.ImportSpec) int {
+	if spec.Comment != nil {
+		return l.tokFile.Line(spec.Comment.End())
+	}
+
+	return l.tokFile.Line(spec.End())
+}
+
+func (l importLines) isBlank(line int) bool {
+	return line > l.lparen && line < l.rparen && !l.occupied[line]
+}
+
+// groups splits the imports of decl where blank lines separate them.
+func (l importLines) groups(decl *ast.GenDecl) [][]*ast.ImportSpec {
+	var groups [][]*ast.ImportSpec
+
+	for i, spec := range decl.Specs {
+		spec := spec.(*ast.ImportSpec)
+
+		if i == 0 || l.hasBlankLine(l.end(decl.Specs[i-1].(*ast.ImportSpec)), l.start(spec)) {
+			groups = append(groups, nil)
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], spec)
+	}
+
+	return groups
+}
+
+func (l importLines) hasBlankLine(after, before int) bool {
+	for line := after + 1; line < before; line++ {
+		if l.isBlank(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteLines removes the lines from first to last, both included.
+func deleteLines(tokFile *token.File, first, last int) analysis.TextEdit {
+	return analysis.TextEdit{
+		Pos: lineStart(tokFile, first),
+		End: lineStart(tokFile, last+1),
+	}
+}
+
+// lineStart returns the position of the first character of line, or the end of
+// the file past its last line.
+func lineStart(tokFile *token.File, line int) token.Pos {
+	if line > tokFile.LineCount() {
+		return token.Pos(tokFile.Base() + tokFile.Size())
+	}
+
+	return tokFile.LineStart(line)
+}
+
+// importDeclText prints an import declaration of specs, the standard library
+// group first.
+func importDeclText(specs []*ast.ImportSpec) string {
+	if len(specs) == 1 {
+		return "import " + importSpecText(specs[0])
+	}
+
+	var std, other []*ast.ImportSpec
+
+	for _, spec := range specs {
+		if isStdImport(importPath(spec)) {
+			std = append(std, spec)
+		} else {
+			other = append(other, spec)
+		}
+	}
+
+	var b strings.Builder
+
+	b.WriteString("import (\n")
+
+	for i, group := range [][]*ast.ImportSpec{std, other} {
+		if i > 0 && len(std) > 0 && len(other) > 0 {
+			b.WriteString("\n")
+		}
+
+		slices.SortStableFunc(group, func(a, b *ast.ImportSpec) int {
+			return strings.Compare(importPath(a), importPath(b))
+		})
+
+		for _, spec := range group {
+			b.WriteString("\t" + importSpecText(spec) + "\n")
+		}
+	}
+
+	b.WriteString(")")
+
+	return b.String()
+}
+
+func importSpecText(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name + " " + spec.Path.Value
+	}
+
+	return spec.Path.Value
+}
+
+func importPath(spec *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(spec.Path.Value)
+
+	return path
+}
+
+// isStdImport reports whether path belongs to the standard library, whose
+// paths have no dot in their first element.
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+
+	return !strings.Contains(first, ".")
+}
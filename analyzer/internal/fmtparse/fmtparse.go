@@ -0,0 +1,291 @@
+// Package fmtparse parses format strings the same way the fmt package does.
+//
+// The parser mirrors fmt's doPrintf: flags, width, precision, explicit argument
+// indexes and '*' operands are all recognized, and the argument each directive
+// consumes is numbered exactly as fmt would number it.
+package fmtparse
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var (
+	ErrNoVerb   = errors.New("format ends before the verb")
+	ErrBadIndex = errors.New("malformed argument index")
+)
+
+type Part interface {
+	isPart()
+}
+
+// Literal is a piece of the format string printed as is.
+type Literal struct {
+	// Start and End are byte offsets of the piece in the format string.
+	Start, End int
+
+	// Text is the printed text. It differs from the source text for "%%".
+	Text string
+}
+
+func (l Literal) isPart() {}
+
+type Flags struct {
+	Plus  bool // '+'
+	Minus bool // '-'
+	Sharp bool // '#'
+	Space bool // ' '
+	Zero  bool // '0'
+}
+
+// Num is a width or a precision of a directive.
+type Num struct {
+	Value   int
+	Present bool
+
+	// FromArg is set for '*'. The actual value is then taken at runtime
+	// from the argument at ArgIndex.
+	FromArg  bool
+	ArgIndex int
+}
+
+// Directive is a single formatting directive, e.g. "%-8.3f" or "%[2]*d".
+type Directive struct {
+	// Start and End are byte offsets of the directive in the format string.
+	Start, End int
+
+	Flags Flags
+	Width Num
+	Prec  Num
+
+	// ArgIndex is the zero-based index of the operand printed by the directive.
+	ArgIndex int
+
+	Verb rune
+}
+
+func (d Directive) isPart() {}
+
+// Plain reports whether the directive has no flags, width or precision.
+func (d Directive) Plain() bool {
+	return d.Flags == Flags{} && !d.Width.Present && !d.Width.FromArg && !d.Prec.Present && !d.Prec.FromArg
+}
+
+type Format struct {
+	Parts []Part
+
+	// Reordered is set when the format uses explicit argument indexes.
+	// fmt does not complain about unused operands in that case.
+	Reordered bool
+
+	// ArgCount is the number of operands consumed by the format,
+	// counting from the last explicit index when the format is reordered.
+	ArgCount int
+}
+
+// Directives returns the directives of the format in order.
+func (f Format) Directives() []Directive {
+	var directives []Directive
+	for _, part := range f.Parts {
+		if d, ok := part.(Directive); ok {
+			directives = append(directives, d)
+		}
+	}
+
+	return directives
+}
+
+// Parse splits format into literal pieces and directives.
+//
+// Argument indexes are not checked against the number of operands,
+// the caller is expected to do that.
+func Parse(format string) (Format, error) {
+	var (
+		res        Format
+		end        = len(format)
+		argNum     int
+		afterIndex bool
+	)
+
+	addLiteral := func(start, end int, text string) {
+		if start == end {
+			return
+		}
+
+		// Adjacent literals are merged, "a%%b" is a single piece.
+		if n := len(res.Parts); n > 0 {
+			if prev, ok := res.Parts[n-1].(Literal); ok && prev.End == start {
+				res.Parts[n-1] = Literal{Start: prev.Start, End: end, Text: prev.Text + text}
+				return
+			}
+		}
+
+		res.Parts = append(res.Parts, Literal{Start: start, End: end, Text: text})
+	}
+
+	for i := 0; i < end; {
+		lasti := i
+		for i < end && format[i] != '%' {
+			i++
+		}
+		addLiteral(lasti, i, format[lasti:i])
+		if i >= end {
+			break
+		}
+
+		start := i
+		d := Directive{Start: start}
+		i++
+
+	flags:
+		for ; i < end; i++ {
+			switch format[i] {
+			case '#':
+				d.Flags.Sharp = true
+			case '0':
+				d.Flags.Zero = true
+			case '+':
+				d.Flags.Plus = true
+			case '-':
+				d.Flags.Minus = true
+			case ' ':
+				d.Flags.Space = true
+			default:
+				break flags
+			}
+		}
+
+		var err error
+
+		argNum, i, afterIndex, err = argNumber(argNum, format, i, &res)
+		if err != nil {
+			return Format{}, err
+		}
+
+		if i < end && format[i] == '*' {
+			i++
+			d.Width = Num{FromArg: true, ArgIndex: argNum}
+			argNum++
+			afterIndex = false
+		} else {
+			d.Width.Value, d.Width.Present, i = parsenum(format, i, end)
+			if afterIndex && d.Width.Present { // "%[3]2d"
+				return Format{}, ErrBadIndex
+			}
+		}
+
+		if i+1 < end && format[i] == '.' {
+			i++
+			if afterIndex { // "%[3].2d"
+				return Format{}, ErrBadIndex
+			}
+
+			argNum, i, afterIndex, err = argNumber(argNum, format, i, &res)
+			if err != nil {
+				return Format{}, err
+			}
+
+			if i < end && format[i] == '*' {
+				i++
+				d.Prec = Num{FromArg: true, ArgIndex: argNum}
+				argNum++
+				afterIndex = false
+			} else {
+				d.Prec.Value, d.Prec.Present, i = parsenum(format, i, end)
+				if !d.Prec.Present {
+					d.Prec.Value = 0
+					d.Prec.Present = true
+				}
+			}
+		}
+
+		if !afterIndex {
+			argNum, i, _, err = argNumber(argNum, format, i, &res)
+			if err != nil {
+				return Format{}, err
+			}
+		}
+
+		if i >= end {
+			return Format{}, ErrNoVerb
+		}
+
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+
+		if verb == '%' {
+			// Percent does not absorb operands and ignores width and precision.
+			addLiteral(start, i, "%")
+			continue
+		}
+
+		d.Verb = verb
+		d.ArgIndex = argNum
+		d.End = i
+		argNum++
+
+		res.Parts = append(res.Parts, d)
+	}
+
+	res.ArgCount = argNum
+
+	return res, nil
+}
+
+// argNumber handles an explicit argument index like "[3]" at format[i:].
+func argNumber(argNum int, format string, i int, res *Format) (int, int, bool, error) {
+	if len(format) <= i || format[i] != '[' {
+		return argNum, i, false, nil
+	}
+
+	res.Reordered = true
+
+	index, wid, ok := parseArgNumber(format[i:])
+	if !ok || index < 0 {
+		return 0, 0, false, ErrBadIndex
+	}
+
+	return index, i + wid, true, nil
+}
+
+func parseArgNumber(format string) (index int, wid int, ok bool) {
+	// There must be at least 3 bytes: [n].
+	if len(format) < 3 {
+		return 0, 1, false
+	}
+
+	// Find closing bracket.
+	for i := 1; i < len(format); i++ {
+		if format[i] == ']' {
+			width, ok, newi := parsenum(format, 1, i)
+			if !ok || newi != i {
+				return 0, i + 1, false
+			}
+			return width - 1, i + 1, true // arg numbers are one-indexed and skip paren.
+		}
+	}
+
+	return 0, 1, false
+}
+
+func tooLarge(x int) bool {
+	const max int = 1e6
+	return x > max || x < -max
+}
+
+// parsenum converts ASCII to integer. num is 0 (and isnum is false) if no number present.
+func parsenum(s string, start, end int) (num int, isnum bool, newi int) {
+	if start >= end {
+		return 0, false, end
+	}
+
+	for newi = start; newi < end && '0' <= s[newi] && s[newi] <= '9'; newi++ {
+		if tooLarge(num) {
+			return 0, false, end // Overflow; crazy long number most likely.
+		}
+		num = num*10 + int(s[newi]-'0')
+		isnum = true
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package fmtparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format   string
+		expected Format
+	}{
+		{
+			format: "%s is %d",
+			expected: Format{
+				Parts: []Part{
+					Directive{Start: 0, End: 2, ArgIndex: 0, Verb: 's'},
+					Literal{Start: 2, End: 6, Text: " is "},
+					Directive{Start: 6, End: 8, ArgIndex: 1, Verb: 'd'},
+				},
+				ArgCount: 2,
+			},
+		},
+		{
+			format: "100%% of %-08.3f",
+			expected: Format{
+				Parts: []Part{
+					Literal{Start: 0, End: 9, Text: "100% of "},
+					Directive{
+						Start:    9,
+						End:      16,
+						Flags:    Flags{Minus: true, Zero: true},
+						Width:    Num{Value: 8, Present: true},
+						Prec:     Num{Value: 3, Present: true},
+						ArgIndex: 0,
+						Verb:     'f',
+					},
+				},
+				ArgCount: 1,
+			},
+		},
+		{
+			format: "%*d|%.*x",
+			expected: Format{
+				Parts: []Part{
+					Directive{Start: 0, End: 3, Width: Num{FromArg: true, ArgIndex: 0}, ArgIndex: 1, Verb: 'd'},
+					Literal{Start: 3, End: 4, Text: "|"},
+					Directive{Start: 4, End: 8, Prec: Num{FromArg: true, ArgIndex: 2}, ArgIndex: 3, Verb: 'x'},
+				},
+				ArgCount: 4,
+			},
+		},
+		{
+			format: "%[2]s %[1]q %s",
+			expected: Format{
+				Parts: []Part{
+					Directive{Start: 0, End: 5, ArgIndex: 1, Verb: 's'},
+					Literal{Start: 5, End: 6, Text: " "},
+					Directive{Start: 6, End: 11, ArgIndex: 0, Verb: 'q'},
+					Literal{Start: 11, End: 12, Text: " "},
+					Directive{Start: 12, End: 14, ArgIndex: 1, Verb: 's'},
+				},
+				Reordered: true,
+				ArgCount:  2,
+			},
+		},
+		{
+			format: "%.f %+v",
+			expected: Format{
+				Parts: []Part{
+					Directive{Start: 0, End: 3, Prec: Num{Value: 0, Present: true}, ArgIndex: 0, Verb: 'f'},
+					Literal{Start: 3, End: 4, Text: " "},
+					Directive{Start: 4, End: 7, Flags: Flags{Plus: true}, ArgIndex: 1, Verb: 'v'},
+				},
+				ArgCount: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.format)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.format, err)
+		}
+
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("%q:\ngot:      %+v\nexpected: %+v", tt.format, got, tt.expected)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]error{
+		"abc %":    ErrNoVerb,
+		"%-5":      ErrNoVerb,
+		"%[0]d":    ErrBadIndex,
+		"%[x]d":    ErrBadIndex,
+		"%[1]2d":   ErrBadIndex,
+		"%[1].2d":  ErrBadIndex,
+		"%[1d":     ErrBadIndex,
+		"%9999999": ErrNoVerb,
+	}
+
+	for format, expected := range tests {
+		_, err := Parse(format)
+		if err != expected {
+			t.Fatalf("%q: got error %v, expected %v", format, err, expected)
+		}
+	}
+}
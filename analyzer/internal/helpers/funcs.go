@@ -0,0 +1,199 @@
+package helpers
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The functions below are copied into the analyzed package as they are,
+// see Helper.Source.
+
+// sprintfBombPrefix puts a base prefix between the sign and the digits
+// of a formatted integer, the way fmt does.
+func sprintfBombPrefix(s, prefix string) string {
+	var sign string
+	if s[0] == '-' || s[0] == '+' || s[0] == ' ' {
+		sign, s = s[:1], s[1:]
+	}
+
+	if prefix == "0" && s[0] == '0' {
+		// An octal number that already starts with a zero is left as is.
+		return sign + s
+	}
+
+	return sign + prefix + s
+}
+
+// sprintfBombSign adds the sign of a non-negative number for the '+' and ' ' flags.
+func sprintfBombSign(s, sign string) string {
+	switch s[0] {
+	case '-':
+		return s
+	case '+':
+		return sign + s[1:]
+	default:
+		return sign + s
+	}
+}
+
+// sprintfBombPad pads s with spaces up to width runes. A negative width pads
+// on the right, the way fmt treats negative '*' widths.
+func sprintfBombPad(s string, width int) string {
+	if width > 1e6 || width < -1e6 {
+		return "%!(BADWIDTH)" + s
+	}
+
+	if width < 0 {
+		if n := -width - utf8.RuneCountInString(s); n > 0 {
+			return s + strings.Repeat(" ", n)
+		}
+
+		return s
+	}
+
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+
+	return s
+}
+
+// sprintfBombPadZeros is like sprintfBombPad, but pads on the left with zeros.
+func sprintfBombPadZeros(s string, width int) string {
+	if width < 0 || width > 1e6 {
+		return sprintfBombPad(s, width)
+	}
+
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return strings.Repeat("0", n) + s
+	}
+
+	return s
+}
+
+// sprintfBombPadNumber pads a formatted number with zeros put after its sign.
+// Infinities and NaN are padded with spaces.
+func sprintfBombPadNumber(s string, width int) string {
+	if width < 0 || width > 1e6 || strings.HasSuffix(s, "Inf") || strings.HasSuffix(s, "NaN") {
+		return sprintfBombPad(s, width)
+	}
+
+	n := width - len(s)
+	if n <= 0 {
+		return s
+	}
+
+	if s[0] == '-' || s[0] == '+' || s[0] == ' ' {
+		return s[:1] + strings.Repeat("0", n) + s[1:]
+	}
+
+	return strings.Repeat("0", n) + s
+}
+
+// sprintfBombBackquote returns s in backquotes when strconv.CanBackquote allows it,
+// and quote(s) otherwise, the way fmt does for %#q.
+func sprintfBombBackquote(s string, quote func(string) string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+
+	return quote(s)
+}
+
+// sprintfBombRune converts an integer to a rune the way fmt does for %q and %c:
+// values out of the Unicode range become utf8.RuneError.
+func sprintfBombRune(c uint64) rune {
+	if c > utf8.MaxRune {
+		return utf8.RuneError
+	}
+
+	return rune(c)
+}
+
+// sprintfBombUnicode formats an integer as a Unicode code point the way fmt does
+// for %U, and for %#U when withChar is set.
+func sprintfBombUnicode(u uint64, withChar bool) string {
+	hex := strings.ToUpper(strconv.FormatUint(u, 16))
+	if len(hex) < 4 {
+		hex = strings.Repeat("0", 4-len(hex)) + hex
+	}
+
+	s := "U+" + hex
+	if withChar && u <= utf8.MaxRune && strconv.IsPrint(rune(u)) {
+		s += " '" + string(rune(u)) + "'"
+	}
+
+	return s
+}
+
+// sprintfBombHex lays out hex-encoded bytes the way fmt does for the ' ' and '#'
+// flags of %x. With space set the bytes are separated by spaces and every one
+// of them gets the prefix, otherwise the prefix goes before the whole string.
+func sprintfBombHex(h string, space bool, prefix string) string {
+	if h == "" {
+		return ""
+	}
+
+	if !space {
+		return prefix + h
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(h); i += 2 {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		sb.WriteString(prefix)
+		sb.WriteString(h[i : i+2])
+	}
+
+	return sb.String()
+}
+
+// sprintfBombWrapped returns what fmt prints for the operand of %w.
+func sprintfBombWrapped(err error) string {
+	if err == nil {
+		return "%!w(<nil>)"
+	}
+
+	return err.Error()
+}
+
+// sprintfBombWrapError is what fmt.Errorf returns for a single %w.
+type sprintfBombWrapError struct {
+	msg string
+	err error
+}
+
+func (e *sprintfBombWrapError) Error() string {
+	return e.msg
+}
+
+func (e *sprintfBombWrapError) Unwrap() error {
+	return e.err
+}
+
+// sprintfBombWrapErrors is what fmt.Errorf returns for several %w.
+type sprintfBombWrapErrors struct {
+	msg  string
+	errs []error
+}
+
+func (e *sprintfBombWrapErrors) Error() string {
+	return e.msg
+}
+
+// Unwrap leaves the nil errors out, as fmt does.
+func (e *sprintfBombWrapErrors) Unwrap() []error {
+	var errs []error
+
+	for _, err := range e.errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
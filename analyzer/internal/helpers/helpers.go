@@ -0,0 +1,148 @@
+// Package helpers holds small functions that are added to the analyzed package
+// when the fmt behavior can't be reproduced with a plain expression.
+//
+// Every helper is emitted at most once per package.
+package helpers
+
+import (
+	_ "embed"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sync"
+)
+
+//go:embed funcs.go
+var funcsSource string
+
+type Helper struct {
+	Name string
+
+	// Imports are the packages the helper source depends on.
+	Imports []string
+
+	// Requires lists the helpers called by this one.
+	Requires []*Helper
+}
+
+var Prefix = &Helper{
+	Name: "sprintfBombPrefix",
+}
+
+var Sign = &Helper{
+	Name: "sprintfBombSign",
+}
+
+var Pad = &Helper{
+	Name:    "sprintfBombPad",
+	Imports: []string{"strings", "unicode/utf8"},
+}
+
+var PadZeros = &Helper{
+	Name:     "sprintfBombPadZeros",
+	Imports:  []string{"strings", "unicode/utf8"},
+	Requires: []*Helper{Pad},
+}
+
+var PadNumber = &Helper{
+	Name:     "sprintfBombPadNumber",
+	Imports:  []string{"strings"},
+	Requires: []*Helper{Pad},
+}
+
+var Backquote = &Helper{
+	Name:    "sprintfBombBackquote",
+	Imports: []string{"strconv"},
+}
+
+var Rune = &Helper{
+	Name:    "sprintfBombRune",
+	Imports: []string{"unicode/utf8"},
+}
+
+var Unicode = &Helper{
+	Name:    "sprintfBombUnicode",
+	Imports: []string{"strconv", "strings", "unicode/utf8"},
+}
+
+var Hex = &Helper{
+	Name:    "sprintfBombHex",
+	Imports: []string{"strings"},
+}
+
+var Wrapped = &Helper{
+	Name: "sprintfBombWrapped",
+}
+
+var WrapError = &Helper{
+	Name: "sprintfBombWrapError",
+}
+
+var WrapErrors = &Helper{
+	Name: "sprintfBombWrapErrors",
+}
+
+// All lists the helpers in the order they are emitted.
+var All = []*Helper{Prefix, Sign, Pad, PadZeros, PadNumber, Backquote, Rune, Unicode, Hex, Wrapped, WrapError, WrapErrors}
+
+var (
+	parseOnce sync.Once
+	sources   map[string]string
+)
+
+// Source returns the declaration of the helper, including its doc comment.
+// The source of a type includes its methods.
+func (h *Helper) Source() string {
+	parseOnce.Do(parseSources)
+
+	source, ok := sources[h.Name]
+	if !ok {
+		panic("unknown helper " + h.Name)
+	}
+
+	return source
+}
+
+func parseSources() {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "funcs.go", funcsSource, parser.ParseComments)
+	if err != nil {
+		panic(err)
+	}
+
+	sources = map[string]string{}
+
+	for _, decl := range file.Decls {
+		var (
+			name string
+			doc  *ast.CommentGroup
+		)
+
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name, doc = d.Name.Name, d.Doc
+
+			if d.Recv != nil {
+				// A method goes after its type.
+				name = d.Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
+				sources[name] += "\n"
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+
+			name, doc = d.Specs[0].(*ast.TypeSpec).Name.Name, d.Doc
+		default:
+			continue
+		}
+
+		start := decl.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+
+		sources[name] += funcsSource[fset.Position(start).Offset:fset.Position(decl.End()).Offset] + "\n"
+	}
+}
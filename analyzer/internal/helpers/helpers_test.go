@@ -0,0 +1,283 @@
+package helpers
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPrefixMatchesFmt(t *testing.T) {
+	t.Parallel()
+
+	for _, i := range []int64{0, 1, 8, -8, 255, -255} {
+		tests := map[string]string{
+			"%#x": sprintfBombPrefix(strconv.FormatInt(i, 16), "0x"),
+			"%#X": sprintfBombPrefix(strings.ToUpper(strconv.FormatInt(i, 16)), "0X"),
+			"%#b": sprintfBombPrefix(strconv.FormatInt(i, 2), "0b"),
+			"%#o": sprintfBombPrefix(strconv.FormatInt(i, 8), "0"),
+			"%O":  sprintfBombPrefix(strconv.FormatInt(i, 8), "0o"),
+			"%#O": sprintfBombPrefix(sprintfBombPrefix(strconv.FormatInt(i, 8), "0"), "0o"),
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, i)
+			if got != expected {
+				t.Fatalf("%s of %d: got: %s, expected: %s", verb, i, got, expected)
+			}
+		}
+	}
+}
+
+func TestPaddingMatchesFmt(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "ab", "-ab", "héllo", "long string"} {
+		tests := map[string]string{
+			"%5s":   sprintfBombPad(s, 5),
+			"%-5s":  sprintfBombPad(s, -5),
+			"%05s":  sprintfBombPadZeros(s, 5),
+			"%-05s": sprintfBombPad(s, -5),
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, s)
+			if got != expected {
+				t.Fatalf("%s of %q: got: %q, expected: %q", verb, s, got, expected)
+			}
+		}
+	}
+
+	for _, b := range []bool{true, false} {
+		got := sprintfBombPadZeros(strconv.FormatBool(b), 6)
+		if expected := fmt.Sprintf("%06t", b); got != expected {
+			t.Fatalf("%%06t of %t: got: %q, expected: %q", b, got, expected)
+		}
+	}
+
+	for _, width := range []int{-8, -1, 0, 1, 8, 1e6 + 1} {
+		for verb, got := range map[string]string{
+			"%*s":  sprintfBombPad("ab", width),
+			"%0*s": sprintfBombPadZeros("ab", width),
+			"%*d":  sprintfBombPad(strconv.Itoa(42), width),
+			"%0*d": sprintfBombPadNumber(strconv.Itoa(-42), width),
+		} {
+			arg := any("ab")
+			if verb[len(verb)-1] == 'd' {
+				arg = 42
+				if verb == "%0*d" {
+					arg = -42
+				}
+			}
+
+			expected := fmt.Sprintf(verb, width, arg)
+			if got != expected {
+				t.Fatalf("%s with width %d: got: %q, expected: %q", verb, width, got, expected)
+			}
+		}
+	}
+}
+
+func TestIntegerFlagsMatchFmt(t *testing.T) {
+	t.Parallel()
+
+	for _, i := range []int64{0, 7, -7, 255, -255, math.MinInt64} {
+		tests := map[string]string{
+			"%5d":     sprintfBombPad(strconv.FormatInt(i, 10), 5),
+			"%-5d|":   sprintfBombPad(strconv.FormatInt(i, 10), -5) + "|",
+			"%05d":    sprintfBombPadNumber(strconv.FormatInt(i, 10), 5),
+			"%+d":     sprintfBombSign(strconv.FormatInt(i, 10), "+"),
+			"% d":     sprintfBombSign(strconv.FormatInt(i, 10), " "),
+			"%+05d":   sprintfBombPadNumber(sprintfBombSign(strconv.FormatInt(i, 10), "+"), 5),
+			"% 05d":   sprintfBombPadNumber(sprintfBombSign(strconv.FormatInt(i, 10), " "), 5),
+			"%-+5d|":  sprintfBombPad(sprintfBombSign(strconv.FormatInt(i, 10), "+"), -5) + "|",
+			"%#08x":   sprintfBombPrefix(sprintfBombPadNumber(strconv.FormatInt(i, 16), 8), "0x"),
+			"%+#08x":  sprintfBombPrefix(sprintfBombPadNumber(sprintfBombSign(strconv.FormatInt(i, 16), "+"), 8), "0x"),
+			"%#8x":    sprintfBombPad(sprintfBombPrefix(strconv.FormatInt(i, 16), "0x"), 8),
+			"%#-8o|":  sprintfBombPad(sprintfBombPrefix(strconv.FormatInt(i, 8), "0"), -8) + "|",
+			"%06o":    sprintfBombPadNumber(strconv.FormatInt(i, 8), 6),
+			"%#06o":   sprintfBombPrefix(sprintfBombPadNumber(strconv.FormatInt(i, 8), 6), "0"),
+			"% 8v":    sprintfBombPad(sprintfBombSign(strconv.FormatInt(i, 10), " "), 8),
+			"%-08d|":  sprintfBombPad(strconv.FormatInt(i, 10), -8) + "|",
+			"%08b":    sprintfBombPadNumber(strconv.FormatInt(i, 2), 8),
+			"%+08X":   sprintfBombPadNumber(sprintfBombSign(strings.ToUpper(strconv.FormatInt(i, 16)), "+"), 8),
+			"%-#10X|": sprintfBombPad(sprintfBombPrefix(strings.ToUpper(strconv.FormatInt(i, 16)), "0X"), -10) + "|",
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, i)
+			if got != expected {
+				t.Fatalf("%s of %d: got: %q, expected: %q", verb, i, got, expected)
+			}
+		}
+	}
+}
+
+func TestFloatFlagsMatchFmt(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []float64{0, 1.5, -1.5, 1234.5678, math.Inf(1), math.Inf(-1), math.NaN()} {
+		tests := map[string]string{
+			"%8.2f":    sprintfBombPad(strconv.FormatFloat(f, 'f', 2, 64), 8),
+			"%-8.2f|":  sprintfBombPad(strconv.FormatFloat(f, 'f', 2, 64), -8) + "|",
+			"%08.2f":   sprintfBombPadNumber(strconv.FormatFloat(f, 'f', 2, 64), 8),
+			"%05.2f":   sprintfBombPadNumber(strconv.FormatFloat(f, 'f', 2, 64), 5),
+			"%+.2f":    sprintfBombSign(strconv.FormatFloat(f, 'f', 2, 64), "+"),
+			"% .2f":    sprintfBombSign(strconv.FormatFloat(f, 'f', 2, 64), " "),
+			"%+08.2f":  sprintfBombPadNumber(sprintfBombSign(strconv.FormatFloat(f, 'f', 2, 64), "+"), 8),
+			"% 08.2f":  sprintfBombPadNumber(sprintfBombSign(strconv.FormatFloat(f, 'f', 2, 64), " "), 8),
+			"%-+08.2f": sprintfBombPad(sprintfBombSign(strconv.FormatFloat(f, 'f', 2, 64), "+"), -8),
+			"%+v":      strconv.FormatFloat(f, 'g', -1, 64),
+			"% v":      sprintfBombSign(strconv.FormatFloat(f, 'g', -1, 64), " "),
+			"%012e":    sprintfBombPadNumber(strconv.FormatFloat(f, 'e', 6, 64), 12),
+			"%+x":      sprintfBombSign(strconv.FormatFloat(f, 'x', -1, 64), "+"),
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, f)
+			if got != expected {
+				t.Fatalf("%s of %v: got: %q, expected: %q", verb, f, got, expected)
+			}
+		}
+	}
+}
+
+func TestQuotingMatchesFmt(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "abc", "héllo", "a`b", "tab\there", "new\nline", "\x00"} {
+		tests := map[string]string{
+			"%#q":  sprintfBombBackquote(s, strconv.Quote),
+			"%#+q": sprintfBombBackquote(s, strconv.QuoteToASCII),
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, s)
+			if got != expected {
+				t.Fatalf("%s of %q: got: %s, expected: %s", verb, s, got, expected)
+			}
+		}
+	}
+
+	for _, i := range []int64{-1, 0, 'A', 0xD800, utf8.MaxRune, utf8.MaxRune + 1, 1<<32 + 'A'} {
+		tests := map[string]string{
+			"%q":  strconv.QuoteRune(sprintfBombRune(uint64(i))),
+			"%+q": strconv.QuoteRuneToASCII(sprintfBombRune(uint64(i))),
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, i)
+			if got != expected {
+				t.Fatalf("%s of %d: got: %s, expected: %s", verb, i, got, expected)
+			}
+		}
+	}
+}
+
+func TestCharactersMatchFmt(t *testing.T) {
+	t.Parallel()
+
+	for _, i := range []int64{-1, 0, 'A', 'é', '\n', 0xD800, 0x1F600, utf8.MaxRune, utf8.MaxRune + 1, 1<<32 + 'A'} {
+		tests := map[string]string{
+			"%c":   string(sprintfBombRune(uint64(i))),
+			"%3c":  sprintfBombPad(string(sprintfBombRune(uint64(i))), 3),
+			"%U":   sprintfBombUnicode(uint64(i), false),
+			"%#U":  sprintfBombUnicode(uint64(i), true),
+			"%08U": sprintfBombPad(sprintfBombUnicode(uint64(i), false), 8),
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, i)
+			if got != expected {
+				t.Fatalf("%s of %d: got: %q, expected: %q", verb, i, got, expected)
+			}
+		}
+	}
+}
+
+func TestHexMatchesFmt(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "a", "hello\xff"} {
+		h := hex.EncodeToString([]byte(s))
+
+		tests := map[string]string{
+			"%x":     h,
+			"%X":     strings.ToUpper(h),
+			"% x":    sprintfBombHex(h, true, ""),
+			"%#x":    sprintfBombHex(h, false, "0x"),
+			"%#X":    strings.ToUpper(sprintfBombHex(h, false, "0x")),
+			"%# x":   sprintfBombHex(h, true, "0x"),
+			"%# X":   strings.ToUpper(sprintfBombHex(h, true, "0x")),
+			"%8x":    sprintfBombPad(h, 8),
+			"%-8x":   sprintfBombPad(h, -8),
+			"%08x":   sprintfBombPadZeros(h, 8),
+			"%-08x":  sprintfBombPad(h, -8),
+			"%#012x": sprintfBombPadZeros(sprintfBombHex(h, false, "0x"), 12),
+		}
+
+		for verb, got := range tests {
+			expected := fmt.Sprintf(verb, s)
+			if got != expected {
+				t.Fatalf("%s of %q: got: %q, expected: %q", verb, s, got, expected)
+			}
+
+			expected = fmt.Sprintf(verb, []byte(s))
+			if got != expected {
+				t.Fatalf("%s of []byte(%q): got: %q, expected: %q", verb, s, got, expected)
+			}
+		}
+	}
+}
+
+func TestWrapErrorsMatchFmt(t *testing.T) {
+	t.Parallel()
+
+	errA, errB := errors.New("a"), errors.New("b")
+
+	single := error(&sprintfBombWrapError{msg: "op: " + errA.Error(), err: errA})
+	expected := fmt.Errorf("op: %w", errA)
+
+	if single.Error() != expected.Error() || !errors.Is(single, errA) || errors.Unwrap(single) != errors.Unwrap(expected) {
+		t.Fatalf("single: got: %v, expected: %v", single, expected)
+	}
+
+	multi := error(&sprintfBombWrapErrors{msg: errA.Error() + ", " + errB.Error(), errs: []error{errA, errB}})
+	expected = fmt.Errorf("%w, %w", errA, errB)
+
+	if multi.Error() != expected.Error() || !errors.Is(multi, errA) || !errors.Is(multi, errB) || errors.Unwrap(multi) != nil {
+		t.Fatalf("multi: got: %v, expected: %v", multi, expected)
+	}
+
+	var errNil error
+
+	single = &sprintfBombWrapError{msg: "op: " + sprintfBombWrapped(errNil), err: errNil}
+	expected = fmt.Errorf("op: %w", errNil)
+
+	if single.Error() != expected.Error() || errors.Unwrap(single) != nil {
+		t.Fatalf("single nil: got: %v, expected: %v", single, expected)
+	}
+
+	multi = &sprintfBombWrapErrors{msg: sprintfBombWrapped(errNil) + ", " + errA.Error(), errs: []error{errNil, errA}}
+	expected = fmt.Errorf("%w, %w", errNil, errA)
+
+	got, want := multi.(interface{ Unwrap() []error }).Unwrap(), expected.(interface{ Unwrap() []error }).Unwrap()
+	if multi.Error() != expected.Error() || len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("multi nil: got: %v %v, expected: %v %v", multi, got, expected, want)
+	}
+}
+
+func TestSources(t *testing.T) {
+	t.Parallel()
+
+	for _, helper := range All {
+		source := helper.Source()
+		if !strings.HasPrefix(source, "// "+helper.Name+" ") {
+			t.Fatalf("%s: source must start with the doc comment, got:\n%s", helper.Name, source)
+		}
+	}
+}
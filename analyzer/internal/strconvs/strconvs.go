@@ -11,17 +11,42 @@ type Itoa struct {
 func (i Itoa) isOp() {}
 
 type FormatInt struct {
+	// Base is 10 when zero.
+	Base int
+
 	CastToInt64 bool
 }
 
 func (f FormatInt) isOp() {}
 
 type FormatUint struct {
+	// Base is 10 when zero.
+	Base int
+
 	CastToUint64 bool
 }
 
 func (f FormatUint) isOp() {}
 
+type FormatBool struct {
+	CastToBool bool
+}
+
+func (f FormatBool) isOp() {}
+
+type QuoteRune struct {
+	// ASCII selects strconv.QuoteRuneToASCII.
+	ASCII bool
+
+	CastToRune bool
+
+	// Clamp converts integers wider than a rune the way fmt does,
+	// turning values out of the Unicode range into utf8.RuneError.
+	Clamp bool
+}
+
+func (q QuoteRune) isOp() {}
+
 type FormatFloat struct {
 	// The format fmt is one of
 	//   - 'b' (-ddddp±ddd, a binary exponent),
@@ -36,6 +61,9 @@ type FormatFloat struct {
 
 	Prec int
 
+	// BitSize is 32 for float32 values and 64 for float64 values.
+	BitSize int
+
 	CastToFloat64 bool
 }
 
@@ -10,6 +10,13 @@ type NoOp struct{}
 
 func (n NoOp) isTransformation() {}
 
+// Literal replaces the operand with a string known at compile time.
+type Literal struct {
+	Value string
+}
+
+func (l Literal) isTransformation() {}
+
 type CallStringMethod struct{}
 
 func (c CallStringMethod) isTransformation() {}
@@ -18,6 +25,15 @@ type CallErrorMethod struct{}
 
 func (c CallErrorMethod) isTransformation() {}
 
+// WrappedError calls Error() on the operand of %w, which may be a nil error.
+type WrappedError struct{}
+
+func (w WrappedError) isTransformation() {}
+
+type CallGoStringMethod struct{}
+
+func (c CallGoStringMethod) isTransformation() {}
+
 type Wrap struct {
 	Wrapper string
 }
@@ -29,3 +45,117 @@ type StrConv struct {
 }
 
 func (s StrConv) isTransformation() {}
+
+// ToUpper upper-cases the string produced by Inner.
+type ToUpper struct {
+	Inner Transformation
+}
+
+func (t ToUpper) isTransformation() {}
+
+// Prefix puts a base prefix like "0x" between the sign and the digits
+// of the integer produced by Inner.
+type Prefix struct {
+	Inner  Transformation
+	Prefix string
+
+	// Signed is set when the integer can be negative.
+	Signed bool
+}
+
+func (p Prefix) isTransformation() {}
+
+// Sign adds Sign ('+' or ' ') to the non-negative number produced by Inner.
+type Sign struct {
+	Inner Transformation
+	Sign  byte
+
+	// Signed is set when the number can be negative.
+	Signed bool
+}
+
+func (s Sign) isTransformation() {}
+
+type Padding int
+
+const (
+	// PadWithSpaces pads on the left, or on the right for a negative width.
+	PadWithSpaces Padding = iota
+	// PadWithZeros pads strings on the left with zeros.
+	PadWithZeros
+	// PadNumberWithZeros puts the zeros after the sign of a number.
+	PadNumberWithZeros
+)
+
+// Pad pads the string produced by Inner up to Width runes.
+type Pad struct {
+	Inner   Transformation
+	Padding Padding
+
+	// Width is negative for padding on the right.
+	Width int
+
+	// WidthFromArg is set for '*' widths. Width is not used then.
+	WidthFromArg bool
+}
+
+func (p Pad) isTransformation() {}
+
+// Quote quotes the string produced by Inner.
+type Quote struct {
+	Inner Transformation
+
+	// ASCII selects strconv.QuoteToASCII (%+q).
+	ASCII bool
+
+	// Backquote prefers a raw string literal when strconv.CanBackquote allows it (%#q).
+	Backquote bool
+}
+
+func (q Quote) isTransformation() {}
+
+// Char converts the integer to the string of a single rune (%c).
+type Char struct {
+	CastToRune bool
+
+	// Clamp turns values that don't fit a rune into utf8.RuneError.
+	Clamp bool
+}
+
+func (c Char) isTransformation() {}
+
+// Unicode formats the integer as a code point like "U+0041" (%U).
+type Unicode struct {
+	// WithChar appends the quoted character when it is printable (%#U).
+	WithChar bool
+
+	CastToUint64 bool
+}
+
+func (u Unicode) isTransformation() {}
+
+// SliceArray slices the byte array so it can be used as a byte slice.
+type SliceArray struct {
+	// Len is the length of the array. A non-addressable array is passed
+	// to a function literal taking [Len]byte to be sliced there.
+	Len         int64
+	Addressable bool
+}
+
+func (s SliceArray) isTransformation() {}
+
+// Hex encodes the bytes produced by Inner with hex.EncodeToString (%x).
+type Hex struct {
+	Inner Transformation
+
+	// FromString is set when Inner produces a string rather than a byte slice.
+	FromString bool
+
+	// Space separates the bytes with spaces (% x).
+	Space bool
+
+	// Prefix is "0x" for %#x. With Space every byte gets it.
+	Prefix string
+}
+
+func (h Hex) isTransformation() {}
@@ -0,0 +1,29 @@
+package knowledge
+
+import "go/types"
+
+// ImplementsFormatter reports whether t implements fmt.Formatter.
+//
+// Unlike the interfaces in Interfaces, fmt.Formatter can't be built from
+// scratch: its method takes fmt.State, and a named type is only identical
+// to itself. So the method is matched by its shape instead.
+func ImplementsFormatter(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, "Format")
+
+	method, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := method.Type().(*types.Signature)
+	if sig.Params().Len() != 2 || sig.Results().Len() != 0 || sig.Variadic() {
+		return false
+	}
+
+	state, ok := sig.Params().At(0).Type().(*types.Named)
+	if !ok || state.Obj().Pkg() == nil || state.Obj().Pkg().Path() != "fmt" || state.Obj().Name() != "State" {
+		return false
+	}
+
+	return types.Identical(sig.Params().At(1).Type(), types.Typ[types.Rune])
+}
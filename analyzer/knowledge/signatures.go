@@ -39,6 +39,13 @@ var Signatures = map[string]*types.Signature{
 		),
 		false,
 	),
+	"(fmt.GoStringer).GoString": types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(),
+		types.NewTuple(
+			types.NewParam(token.NoPos, nil, "", types.Typ[types.String]),
+		),
+		false,
+	),
 }
 
 var Interfaces = map[string]*types.Interface{
@@ -49,5 +56,12 @@ var Interfaces = map[string]*types.Interface{
 		nil,
 	).Complete(),
 
+	"fmt.GoStringer": types.NewInterfaceType(
+		[]*types.Func{
+			types.NewFunc(token.NoPos, nil, "GoString", Signatures["(fmt.GoStringer).GoString"]),
+		},
+		nil,
+	).Complete(),
+
 	"error": types.Universe.Lookup("error").Type().Underlying().(*types.Interface),
 }
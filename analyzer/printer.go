@@ -0,0 +1,430 @@
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/edge"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const (
+	// maxLineWidth is the width past which a concatenation is broken across
+	// lines, a tab counting as tabWidth columns.
+	maxLineWidth = 100
+	tabWidth     = 4
+)
+
+// printer prints the code of a rewrite. The generated nodes go on a single line,
+// with the parentheses their parents need, while the operands taken from the
+// source are copied the way they are written there, comments included.
+type printer struct {
+	fset      *token.FileSet
+	typesInfo *types.Info
+	tokFile   *token.File
+	src       []byte // content of the file, nil if it couldn't be read
+
+	indent    string // indentation of the line the code starts on
+	buf       strings.Builder
+	col       int  // width of the current line
+	breakable bool // whether the next concatenation may be broken across lines
+}
+
+// newPrinter returns a printer for code replacing the source at pos.
+func newPrinter(fset *token.FileSet, typesInfo *types.Info, src []byte, pos token.Pos) *printer {
+	p := &printer{
+		fset:      fset,
+		typesInfo: typesInfo,
+		tokFile:   fset.File(pos),
+		src:       src,
+		breakable: true,
+	}
+
+	position := fset.Position(pos)
+
+	if src == nil || position.Offset > len(src) {
+		// The statements are gofmt-ed, so the column tells how many tabs indent them.
+		p.indent = strings.Repeat("\t", position.Column-1)
+		p.col = lineWidth(p.indent)
+
+		return p
+	}
+
+	line := src[position.Offset-(position.Column-1) : position.Offset]
+
+	p.indent = string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+	p.col = lineWidth(string(line))
+
+	return p
+}
+
+// print prints node as the child of a node binding its operands with prec, the
+// way token.Token.Precedence ranks them.
+func (p *printer) print(node ast.Node, prec int) string {
+	p.node(node, prec)
+
+	return p.buf.String()
+}
+
+func (p *printer) write(s string) {
+	p.buf.WriteString(s)
+
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.col = lineWidth(s[i+1:])
+	} else {
+		p.col += lineWidth(s)
+	}
+}
+
+func (p *printer) node(node ast.Node, prec int) {
+	if expr, ok := node.(ast.Expr); ok {
+		if text, ok := p.source(expr); ok {
+			p.parenthesized(needsParens(expr, prec), func() { p.write(text) })
+			return
+		}
+	}
+
+	switch n := node.(type) {
+	case *ast.Ident:
+		p.write(n.Name)
+	case *ast.BasicLit:
+		p.write(n.Value)
+	case *ast.BinaryExpr:
+		p.parenthesized(needsParens(n, prec), func() { p.binaryExpr(n) })
+	case *ast.UnaryExpr:
+		p.parenthesized(needsParens(n, prec), func() {
+			p.write(n.Op.String())
+			p.node(n.X, token.UnaryPrec)
+		})
+	case *ast.StarExpr:
+		p.parenthesized(needsParens(n, prec), func() {
+			p.write("*")
+			p.node(n.X, token.UnaryPrec)
+		})
+	case *ast.ParenExpr:
+		p.parenthesized(true, func() { p.node(n.X, token.LowestPrec) })
+	case *ast.SelectorExpr:
+		p.node(n.X, token.HighestPrec)
+		p.write("." + n.Sel.Name)
+	case *ast.IndexExpr:
+		p.node(n.X, token.HighestPrec)
+		p.write("[")
+		p.node(n.Index, token.LowestPrec)
+		p.write("]")
+	case *ast.SliceExpr:
+		p.node(n.X, token.HighestPrec)
+		p.write("[")
+		p.optional(n.Low)
+		p.write(":")
+		p.optional(n.High)
+		if n.Slice3 {
+			p.write(":")
+			p.optional(n.Max)
+		}
+		p.write("]")
+	case *ast.CallExpr:
+		p.node(n.Fun, token.HighestPrec)
+		p.write("(")
+		p.list(n.Args)
+		if n.Ellipsis.IsValid() {
+			p.write("...")
+		}
+		p.write(")")
+	case *ast.CompositeLit:
+		p.node(n.Type, token.LowestPrec)
+		p.write("{")
+		p.list(n.Elts)
+		p.write("}")
+	case *ast.KeyValueExpr:
+		p.node(n.Key, token.LowestPrec)
+		p.write(": ")
+		p.node(n.Value, token.LowestPrec)
+	case *ast.ArrayType:
+		p.write("[")
+		p.optional(n.Len)
+		p.write("]")
+		p.node(n.Elt, token.LowestPrec)
+	case *ast.FuncLit:
+		// Kept on one line, which gofmt leaves alone for a short body.
+		p.write("func")
+		p.fields(n.Type.Params, true)
+		if n.Type.Results != nil {
+			p.write(" ")
+			p.fields(n.Type.Results, len(n.Type.Results.List) > 1 || len(n.Type.Results.List[0].Names) > 0)
+		}
+		p.write(" { ")
+		for i, stmt := range n.Body.List {
+			if i > 0 {
+				p.write("; ")
+			}
+			p.node(stmt, token.LowestPrec)
+		}
+		p.write(" }")
+	case *ast.ReturnStmt:
+		p.write("return")
+		if len(n.Results) > 0 {
+			p.write(" ")
+			p.list(n.Results)
+		}
+	case *ast.AssignStmt:
+		p.list(n.Lhs)
+		p.write(" " + n.Tok.String() + " ")
+		p.list(n.Rhs)
+	default:
+		p.write(formatAnyNode(p.fset, n))
+	}
+}
+
+func (p *printer) parenthesized(parens bool, print func()) {
+	if parens {
+		p.write("(")
+	}
+
+	print()
+
+	if parens {
+		p.write(")")
+	}
+}
+
+func (p *printer) optional(expr ast.Expr) {
+	if expr != nil {
+		p.node(expr, token.LowestPrec)
+	}
+}
+
+func (p *printer) list(exprs []ast.Expr) {
+	for i, expr := range exprs {
+		if i > 0 {
+			p.write(", ")
+		}
+
+		p.node(expr, token.LowestPrec)
+	}
+}
+
+func (p *printer) fields(list *ast.FieldList, parens bool) {
+	p.parenthesized(parens, func() {
+		for i, field := range list.List {
+			if i > 0 {
+				p.write(", ")
+			}
+
+			for j, name := range field.Names {
+				if j > 0 {
+					p.write(", ")
+				}
+				p.write(name.Name)
+			}
+
+			if len(field.Names) > 0 {
+				p.write(" ")
+			}
+
+			p.node(field.Type, token.LowestPrec)
+		}
+	})
+}
+
+// binaryExpr prints the operands of a chain of the same operator one after
+// the other. The first concatenation too long for its line is broken after an
+// operator, the next lines indented by an extra tab, as gofmt lays them out.
+func (p *printer) binaryExpr(expr *ast.BinaryExpr) {
+	var (
+		operands  = []ast.Expr{expr.Y}
+		breakable = p.breakable && expr.Op == token.ADD
+		prec      = expr.Op.Precedence()
+	)
+
+	p.breakable = false
+
+	x := expr.X
+	for {
+		inner, ok := x.(*ast.BinaryExpr)
+		if !ok || inner.Op != expr.Op {
+			break
+		}
+
+		if _, ok := p.source(inner); ok {
+			break
+		}
+
+		operands = append(operands, inner.Y)
+		x = inner.X
+	}
+
+	operands = append(operands, x)
+
+	for i := len(operands) - 1; i >= 0; i-- {
+		// The generated concatenations hold strings, which don't need the
+		// parentheses of a sum on the right.
+		operandPrec := prec + 1
+		if i == len(operands)-1 || expr.Op == token.ADD {
+			operandPrec = prec
+		}
+
+		text := p.sub(operands[i], operandPrec)
+
+		if i < len(operands)-1 {
+			// An operand spanning lines keeps the indentation it has in the source.
+			if breakable && !strings.Contains(text, "\n") && p.col+len(" + ")+lineWidth(text) > maxLineWidth {
+				p.write(" " + expr.Op.String() + "\n" + p.indent + "\t")
+			} else {
+				p.write(" " + expr.Op.String() + " ")
+			}
+		}
+
+		p.write(text)
+	}
+}
+
+// sub prints expr apart, without breaking it across lines.
+func (p *printer) sub(expr ast.Expr, prec int) string {
+	sub := &printer{
+		fset:      p.fset,
+		typesInfo: p.typesInfo,
+		tokFile:   p.tokFile,
+		src:       p.src,
+		indent:    p.indent,
+	}
+
+	return sub.print(expr, prec)
+}
+
+// source returns the text of expr if it comes from the file.
+func (p *printer) source(expr ast.Expr) (string, bool) {
+	if p.src == nil || !isSourceExpr(p.typesInfo, expr) {
+		return "", false
+	}
+
+	if p.fset.File(expr.Pos()) != p.tokFile || p.fset.File(expr.End()) != p.tokFile {
+		return "", false
+	}
+
+	start, end := p.tokFile.Offset(expr.Pos()), p.tokFile.Offset(expr.End())
+	if start > end || end > len(p.src) {
+		return "", false
+	}
+
+	return string(p.src[start:end]), true
+}
+
+// losesComments reports whether the rewrite of call would lose a comment: only
+// the comments inside the arguments in kept, copied from the source, remain.
+func losesComments(cursor inspector.Cursor, call *ast.CallExpr, kept []ast.Expr) bool {
+	var file *ast.File
+	for cur := range cursor.Enclosing((*ast.File)(nil)) {
+		file = cur.Node().(*ast.File)
+	}
+
+	if file == nil || !call.Lparen.IsValid() {
+		return false
+	}
+
+	first := sort.Search(len(file.Comments), func(i int) bool {
+		return file.Comments[i].End() > call.Lparen
+	})
+
+	for _, group := range file.Comments[first:] {
+		if group.Pos() >= call.Rparen {
+			break
+		}
+
+		inside := slices.ContainsFunc(kept, func(expr ast.Expr) bool {
+			return expr.Pos() <= group.Pos() && group.End() <= expr.End()
+		})
+		if !inside {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keptArgs returns the arguments of call the rewrite copies: all of them but the
+// format and the dropped operands.
+func keptArgs(call *ast.CallExpr, analyzed analyzedSprintfCall) []ast.Expr {
+	var dropped []ast.Expr
+	for _, index := range analyzed.droppedOperands() {
+		dropped = append(dropped, analyzed.operands[index])
+	}
+
+	var kept []ast.Expr
+
+	for _, arg := range call.Args {
+		if arg != analyzed.formatExpr && !slices.Contains(dropped, arg) {
+			kept = append(kept, arg)
+		}
+	}
+
+	return kept
+}
+
+// isSourceExpr reports whether expr was parsed from the source rather than
+// built by a rewrite, the generated nodes have no type information.
+func isSourceExpr(typesInfo *types.Info, expr ast.Expr) bool {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return typesInfo.Uses[ident] != nil || typesInfo.Defs[ident] != nil
+	}
+
+	_, ok := typesInfo.Types[expr]
+
+	return ok && expr.Pos().IsValid()
+}
+
+// needsParens reports whether expr must be parenthesized as the child of a node
+// binding its operands with prec.
+func needsParens(expr ast.Expr, prec int) bool {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		return e.Op.Precedence() < prec
+	case *ast.UnaryExpr, *ast.StarExpr:
+		return prec > token.UnaryPrec
+	default:
+		return false
+	}
+}
+
+// contextPrecedence returns the precedence the parent of the node at cursor
+// binds it with, for the expression replacing the node.
+func contextPrecedence(typesInfo *types.Info, cursor inspector.Cursor) int {
+	switch k, _ := cursor.ParentEdge(); k {
+	case edge.SelectorExpr_X, edge.IndexExpr_X, edge.IndexListExpr_X, edge.SliceExpr_X,
+		edge.TypeAssertExpr_X, edge.CallExpr_Fun:
+		return token.HighestPrec
+	case edge.UnaryExpr_X, edge.StarExpr_X:
+		return token.UnaryPrec
+	case edge.BinaryExpr_X:
+		return cursor.Parent().Node().(*ast.BinaryExpr).Op.Precedence()
+	case edge.BinaryExpr_Y:
+		parent := cursor.Parent().Node().(*ast.BinaryExpr)
+
+		// Concatenating strings is associative.
+		if isString, _ := isStringKind(typesInfo, parent); isString && parent.Op == token.ADD {
+			return parent.Op.Precedence()
+		}
+
+		return parent.Op.Precedence() + 1
+	default:
+		return token.LowestPrec
+	}
+}
+
+func lineWidth(s string) int {
+	return len(s) + strings.Count(s, "\t")*(tabWidth-1)
+}
+
+func formatAnyNode(fset *token.FileSet, node ast.Node) string {
+	buf := new(bytes.Buffer)
+	if err := format.Node(buf, fset, node); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,228 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/fmtparse"
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/transform"
+)
+
+// strconvAppendFuncs maps the strconv functions producing a string to the ones
+// appending the same bytes to a slice.
+var strconvAppendFuncs = map[string]string{
+	"Itoa":             "AppendInt",
+	"FormatInt":        "AppendInt",
+	"FormatUint":       "AppendUint",
+	"FormatBool":       "AppendBool",
+	"FormatFloat":      "AppendFloat",
+	"Quote":            "AppendQuote",
+	"QuoteToASCII":     "AppendQuoteToASCII",
+	"QuoteRune":        "AppendQuoteRune",
+	"QuoteRuneToASCII": "AppendQuoteRuneToASCII",
+}
+
+// processSprintfAppend turns append(buf, fmt.Sprintf(...)...) into nested
+// appends of the pieces, using strconv.Append* where possible. The impure
+// operands are hoisted like for Fprintf.
+func processSprintfAppend(
+	typesInfo *types.Info,
+	cursor inspector.Cursor,
+	filePkgOut *packagesFileResult,
+) (sprintfRewrite, bool) {
+	var zero sprintfRewrite
+
+	outer := cursor.Parent().Node().(*ast.CallExpr)
+	if len(outer.Args) != 2 || !outer.Ellipsis.IsValid() || filePkgOut.overlapsRewrite(outer) {
+		return zero, false
+	}
+
+	call := cursor.Node().(*ast.CallExpr)
+	if mayAliasDestination(typesInfo, outer.Args[0], call.Args[1:]) {
+		return zero, false
+	}
+
+	analyzed, rewrite, ok := prepareSprintfCall(typesInfo, cursor, analyzeSprintfCall, operandsToHoistBeforeWrites, filePkgOut)
+	if !ok {
+		return zero, false
+	}
+
+	var (
+		res      = outer.Args[0]
+		argIndex int
+		text     strings.Builder // the pieces known since the last append of a value
+		deps     = filePkgOut.newDependencies()
+	)
+
+	appendString := func(s ast.Expr) {
+		res = &ast.CallExpr{
+			Fun:      &ast.Ident{Name: "append"},
+			Args:     []ast.Expr{res, s},
+			Ellipsis: outer.Ellipsis,
+		}
+	}
+
+	flush := func() {
+		if text.Len() > 0 {
+			appendString(newStringLit(text.String(), analyzed.raw))
+			text.Reset()
+		}
+	}
+
+	for _, part := range analyzed.format.Parts {
+		switch p := part.(type) {
+		case fmtparse.Literal:
+			text.WriteString(p.Text)
+		case fmtparse.Directive:
+			arg := analyzed.args[argIndex]
+			argIndex++
+
+			if arg.folded {
+				text.WriteString(arg.text)
+				continue
+			}
+
+			if literal, ok := arg.transformation.(transform.Literal); ok {
+				text.WriteString(literal.Value)
+				continue
+			}
+
+			flush()
+
+			if isByteSliceToString(typesInfo, arg) {
+				appendString(arg.value)
+				continue
+			}
+
+			value := transformValue(arg, arg.transformation, &deps)
+
+			if appended, ok := strconvAppend(res, value, deps); ok {
+				res = appended
+				continue
+			}
+
+			appendString(value)
+		}
+	}
+
+	flush()
+
+	if !importsVisible(typesInfo, cursor, deps) {
+		return zero, false
+	}
+
+	filePkgOut.importRefs["fmt"]--
+	filePkgOut.dropReferences(typesInfo, analyzed.formatExpr)
+	filePkgOut.addDependencies(deps)
+
+	for _, index := range analyzed.droppedOperands() {
+		filePkgOut.dropOperand(typesInfo, analyzed.operands[index])
+	}
+
+	rewrite.expr = res
+	rewrite.target = outer
+
+	return rewrite, true
+}
+
+// mayAliasDestination reports whether an operand may share its bytes with the
+// slice appended to. fmt reads every operand before appending, while the
+// rewrite appends the pieces one at a time. Only the values read through
+// memory are a concern, and only those referring to a variable of the
+// destination are known to possibly alias it.
+func mayAliasDestination(typesInfo *types.Info, dst ast.Expr, operands []ast.Expr) bool {
+	vars := map[*types.Var]bool{}
+
+	ast.Inspect(dst, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			if v, ok := typesInfo.Uses[ident].(*types.Var); ok {
+				vars[v] = true
+			}
+		}
+
+		return true
+	})
+
+	for _, operand := range operands {
+		if _, ok := typesInfo.TypeOf(operand).Underlying().(*types.Basic); ok {
+			continue // a copy, and strings are immutable
+		}
+
+		aliases := false
+
+		ast.Inspect(operand, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok {
+				if v, ok := typesInfo.Uses[ident].(*types.Var); ok && vars[v] {
+					aliases = true
+				}
+			}
+
+			return !aliases
+		})
+
+		if aliases {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBuiltinAppend(typesInfo *types.Info, call *ast.CallExpr) bool {
+	ident, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	builtin, ok := typesInfo.Uses[ident].(*types.Builtin)
+
+	return ok && builtin.Name() == "append"
+}
+
+// isByteSliceToString reports whether the directive only converts a byte slice
+// to a string, which is pointless when the bytes are written anyway.
+func isByteSliceToString(typesInfo *types.Info, arg sprintfArg) bool {
+	wrap, ok := arg.transformation.(transform.Wrap)
+
+	return ok && wrap.Wrapper == "string" && isByteSlice(typesInfo.TypeOf(arg.value))
+}
+
+// strconvAppend turns a call like strconv.FormatInt(x, 10) produced by
+// transformValue into the strconv.AppendInt(dst, x, 10) counterpart.
+func strconvAppend(dst, value ast.Expr, deps dependencies) (ast.Expr, bool) {
+	call, ok := value.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != deps.name("strconv") {
+		return nil, false
+	}
+
+	name, ok := strconvAppendFuncs[sel.Sel.Name]
+	if !ok {
+		return nil, false
+	}
+
+	args := slices.Clone(call.Args)
+	if sel.Sel.Name == "Itoa" {
+		args = []ast.Expr{newConversion("int64", args[0]), baseLit(10)}
+	}
+
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   sel.X,
+			Sel: &ast.Ident{Name: name},
+		},
+		Args: append([]ast.Expr{dst}, args...),
+	}, true
+}
@@ -0,0 +1,284 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/ast/edge"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/fmtparse"
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/transform"
+)
+
+// ProcessFprintfCall turns fmt.Fprintf on a strings.Builder, bytes.Buffer or
+// bufio.Writer into a write per piece of the format. The call must be a statement
+// of its own, as the results of the writes differ from the ones of Fprintf.
+func ProcessFprintfCall(
+	typesInfo *types.Info,
+	cursor inspector.Cursor,
+	filePkgOut *packagesFileResult,
+) (sprintfRewrite, bool) {
+	call := cursor.Node().(*ast.CallExpr)
+
+	if len(call.Args) < 2 {
+		return sprintfRewrite{}, false
+	}
+
+	return processWrites(typesInfo, cursor.Parent(), call.Args[0], &ast.CallExpr{
+		Args:     call.Args[1:],
+		Ellipsis: call.Ellipsis,
+	}, filePkgOut)
+}
+
+// ProcessSprintfDestination handles a Sprintf call whose result goes straight
+// into a writer or a byte slice: the pieces are written or appended there one
+// by one instead of being concatenated first.
+func ProcessSprintfDestination(
+	typesInfo *types.Info,
+	cursor inspector.Cursor,
+	filePkgOut *packagesFileResult,
+) (sprintfRewrite, bool) {
+	var zero sprintfRewrite
+
+	if k, i := cursor.ParentEdge(); k != edge.CallExpr_Args || i != len(cursor.Parent().Node().(*ast.CallExpr).Args)-1 {
+		return zero, false
+	}
+
+	outer := cursor.Parent().Node().(*ast.CallExpr)
+
+	if isBuiltinAppend(typesInfo, outer) {
+		return processSprintfAppend(typesInfo, cursor, filePkgOut)
+	}
+
+	// sb.WriteString(fmt.Sprintf(...))
+	sel, ok := outer.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "WriteString" || len(outer.Args) != 1 {
+		return zero, false
+	}
+
+	if selection, ok := typesInfo.Selections[sel]; !ok || selection.Kind() != types.MethodVal {
+		return zero, false
+	}
+
+	rewrite, ok := processWrites(typesInfo, cursor.Parent().Parent(), sel.X, cursor.Node().(*ast.CallExpr), filePkgOut)
+	if !ok {
+		return zero, false
+	}
+
+	rewrite.target = outer
+
+	return rewrite, true
+}
+
+// processWrites replaces stmt, which prints the format call to writer, with
+// a write per piece of the format.
+func processWrites(
+	typesInfo *types.Info,
+	stmt inspector.Cursor,
+	writer ast.Expr,
+	call *ast.CallExpr,
+	filePkgOut *packagesFileResult,
+) (sprintfRewrite, bool) {
+	var zero sprintfRewrite
+
+	appendable, ok := knownWriter(typesInfo, writer)
+	if !ok {
+		return zero, false
+	}
+
+	receiver, ok := writerReceiver(typesInfo, writer)
+	if !ok {
+		return zero, false
+	}
+
+	if _, ok := stmt.Node().(*ast.ExprStmt); !ok {
+		return zero, false
+	}
+
+	switch k, _ := stmt.ParentEdge(); k {
+	case edge.BlockStmt_List, edge.CaseClause_Body, edge.CommClause_Body:
+	default:
+		return zero, false
+	}
+
+	analyzed, ok := analyzeSprintfCall(typesInfo, call)
+	if !ok {
+		return zero, false
+	}
+
+	analyzed.foldConstants(typesInfo)
+
+	if !analyzed.canDropOperands(typesInfo, filePkgOut.varUses) {
+		return zero, false
+	}
+
+	// The statement is the Fprintf or WriteString call, the writer is kept too.
+	outer, ok := ast.Unparen(stmt.Node().(*ast.ExprStmt).X).(*ast.CallExpr)
+	if !ok || losesComments(stmt, outer, append(keptArgs(call, analyzed), writer)) {
+		return zero, false
+	}
+
+	hoisted, ok := operandsToHoistBeforeWrites(typesInfo, analyzed.operands, analyzed)
+	if !ok {
+		return zero, false
+	}
+
+	var rewrite sprintfRewrite
+
+	if len(hoisted) > 0 {
+		rewrite.stmt = stmt.Node().(ast.Stmt)
+
+		rewrite.temporaries, ok = newTemporaries(typesInfo, stmt, analyzed.operands, hoisted, filePkgOut)
+		if !ok {
+			return zero, false
+		}
+
+		for i, index := range hoisted {
+			analyzed.replaceOperand(index, rewrite.temporaries[i].Lhs[0])
+		}
+	}
+
+	var (
+		argIndex int
+		text     strings.Builder // the pieces known since the last write of a value
+		deps     = filePkgOut.newDependencies()
+	)
+
+	flush := func() {
+		if text.Len() > 0 {
+			rewrite.writes = append(rewrite.writes, writeLiteral(receiver, text.String(), analyzed.raw))
+			text.Reset()
+		}
+	}
+
+	for _, part := range analyzed.format.Parts {
+		switch p := part.(type) {
+		case fmtparse.Literal:
+			text.WriteString(p.Text)
+		case fmtparse.Directive:
+			arg := analyzed.args[argIndex]
+			argIndex++
+
+			if arg.folded {
+				text.WriteString(arg.text)
+				continue
+			}
+
+			if literal, ok := arg.transformation.(transform.Literal); ok {
+				text.WriteString(literal.Value)
+				continue
+			}
+
+			flush()
+			rewrite.writes = append(rewrite.writes, writeValue(typesInfo, receiver, arg, appendable, &deps))
+		}
+	}
+
+	flush()
+
+	if !importsVisible(typesInfo, stmt, deps) {
+		return zero, false
+	}
+
+	filePkgOut.importRefs["fmt"]--
+	filePkgOut.dropReferences(typesInfo, analyzed.formatExpr)
+	filePkgOut.addDependencies(deps)
+
+	for _, index := range analyzed.droppedOperands() {
+		filePkgOut.dropOperand(typesInfo, analyzed.operands[index])
+	}
+
+	return rewrite, true
+}
+
+// knownWriter reports whether expr is one of the writers whose methods are
+// known, or a pointer to one. The first result is set for the writers that have
+// AvailableBuffer, so that strconv can append to them directly.
+func knownWriter(typesInfo *types.Info, expr ast.Expr) (bool, bool) {
+	tv, ok := typesInfo.Types[expr]
+	if !ok || tv.Type == nil {
+		return false, false
+	}
+
+	t := types.Unalias(tv.Type)
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = types.Unalias(ptr.Elem())
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false, false
+	}
+
+	switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+	case "strings.Builder":
+		return false, true
+	case "bytes.Buffer", "bufio.Writer":
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// writerReceiver returns the expression to call the write methods on. It is
+// repeated for every write, so it must be free of side effects.
+func writerReceiver(typesInfo *types.Info, expr ast.Expr) (ast.Expr, bool) {
+	if u, ok := ast.Unparen(expr).(*ast.UnaryExpr); ok && u.Op == token.AND {
+		// The methods take the address of the variable themselves.
+		return u.X, isSafeToRepeat(typesInfo, u.X)
+	}
+
+	return expr, isSafeToRepeat(typesInfo, expr)
+}
+
+func writeLiteral(receiver ast.Expr, text string, raw bool) *ast.CallExpr {
+	if len(text) == 1 && text[0] < utf8.RuneSelf {
+		return newMethodCall(receiver, "WriteByte", &ast.BasicLit{
+			Kind:  token.CHAR,
+			Value: strconv.QuoteRune(rune(text[0])),
+		})
+	}
+
+	return newMethodCall(receiver, "WriteString", newStringLit(text, raw))
+}
+
+func writeValue(
+	typesInfo *types.Info,
+	receiver ast.Expr,
+	arg sprintfArg,
+	appendable bool,
+	deps *dependencies,
+) *ast.CallExpr {
+	if isByteSliceToString(typesInfo, arg) {
+		return newMethodCall(receiver, "Write", arg.value)
+	}
+
+	value := transformValue(arg, arg.transformation, deps)
+
+	if appendable {
+		if appended, ok := strconvAppend(newMethodCall(receiver, "AvailableBuffer"), value, *deps); ok {
+			return newMethodCall(receiver, "Write", appended)
+		}
+	}
+
+	return newMethodCall(receiver, "WriteString", value)
+}
+
+func newMethodCall(receiver ast.Expr, method string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   receiver,
+			Sel: &ast.Ident{Name: method},
+		},
+		Args: args,
+	}
+}
+
+func newConversion(typeName string, value ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: &ast.Ident{Name: typeName}, Args: []ast.Expr{value}}
+}
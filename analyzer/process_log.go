@@ -0,0 +1,206 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/fmtparse"
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/transform"
+)
+
+// logFuncs maps the printf-style functions and methods of log and testing to
+// their counterparts taking the operands the way fmt.Sprint does.
+var logFuncs = map[string]string{
+	"log.Printf":     "Print",
+	"log.Fatalf":     "Fatal",
+	"log.Panicf":     "Panic",
+	"testing.Logf":   "Log",
+	"testing.Errorf": "Error",
+	"testing.Fatalf": "Fatal",
+}
+
+// ProcessLogCall turns a call like log.Printf("id=%d", id) into
+// log.Print("id=" + strconv.Itoa(id)). A single string is printed the same
+// by both functions, log adds the final newline in both cases.
+//
+// The printf wrappers mapped with the printf-funcs flag are handled the same
+// way, the operands before the format are kept as they are.
+func ProcessLogCall(
+	typesInfo *types.Info,
+	cfg *config,
+	cursor inspector.Cursor,
+	filePkgOut *packagesFileResult,
+) (sprintfRewrite, bool) {
+	var zero sprintfRewrite
+
+	call := cursor.Node().(*ast.CallExpr)
+
+	fn, ok := typeutil.Callee(typesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return zero, false
+	}
+
+	var (
+		name, found = cfg.printfFuncs[fn.FullName()]
+		analyze     = analyzeSprintfCall
+	)
+
+	if logName, ok := logFuncs[fn.Pkg().Path()+"."+fn.Name()]; ok && cfg.logCalls {
+		name, found = logName, true
+
+		if fn.Pkg().Path() == "testing" {
+			analyze = analyzeTestingLogCall
+		}
+	}
+
+	if !found {
+		return zero, false
+	}
+
+	formatIndex, ok := printfFormatIndex(fn)
+	if !ok || len(call.Args) <= formatIndex {
+		return zero, false
+	}
+
+	fun, ok := siblingFunc(typesInfo, cursor, fn, name)
+	if !ok {
+		return zero, false
+	}
+
+	leading := call.Args[:formatIndex]
+
+	analyzed, rewrite, ok := prepareSprintfCall(typesInfo, cursor, func(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool) {
+		return analyze(typesInfo, &ast.CallExpr{Args: call.Args[formatIndex:], Ellipsis: call.Ellipsis})
+	}, operandsToHoist, filePkgOut)
+	if !ok {
+		return zero, false
+	}
+
+	for _, arg := range leading {
+		if len(rewrite.temporaries) > 0 && !isSafeToRepeat(typesInfo, arg) {
+			// The temporaries would be evaluated before the operand.
+			return zero, false
+		}
+	}
+
+	rewrite, ok = finishSprintfCall(typesInfo, cursor, analyzed, rewrite, filePkgOut)
+	if !ok {
+		return zero, false
+	}
+
+	rewrite.expr = &ast.CallExpr{
+		Fun:  fun,
+		Args: append(slices.Clone(leading), rewrite.expr),
+	}
+
+	return rewrite, true
+}
+
+// printfFormatIndex returns the index of the format parameter of a printf-like
+// function: a string followed by the variadic operands.
+func printfFormatIndex(fn *types.Func) (int, bool) {
+	sig := fn.Signature()
+
+	n := sig.Params().Len()
+	if !sig.Variadic() || n < 2 {
+		return 0, false
+	}
+
+	basic, ok := sig.Params().At(n - 2).Type().Underlying().(*types.Basic)
+	if !ok || basic.Kind() != types.String {
+		return 0, false
+	}
+
+	return n - 2, true
+}
+
+// siblingFunc returns the expression calling the function or method name of
+// the same package as fn, in place of the callee of the call at cursor. A type
+// embedding a *log.Logger may declare its own Print, for example.
+func siblingFunc(typesInfo *types.Info, cursor inspector.Cursor, fn *types.Func, name string) (ast.Expr, bool) {
+	call := cursor.Node().(*ast.CallExpr)
+
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		// A function of the package itself.
+		scope := enclosingScope(typesInfo, cursor)
+		if scope == nil {
+			return nil, false
+		}
+
+		_, obj := scope.LookupParent(name, call.Pos())
+		sibling, ok := obj.(*types.Func)
+		if !ok || sibling.Pkg() != fn.Pkg() {
+			return nil, false
+		}
+
+		return &ast.Ident{Name: name}, true
+	case *ast.SelectorExpr:
+		if _, ok := typesInfo.Selections[fun]; !ok {
+			// A package-level function.
+			if fn.Pkg().Scope().Lookup(name) == nil {
+				return nil, false
+			}
+		} else {
+			obj, _, _ := types.LookupFieldOrMethod(typesInfo.TypeOf(fun.X), true, fn.Pkg(), name)
+			if sibling, ok := obj.(*types.Func); !ok || sibling.Pkg() != fn.Pkg() {
+				return nil, false
+			}
+		}
+
+		return &ast.SelectorExpr{X: fun.X, Sel: &ast.Ident{Name: name}}, true
+	default:
+		return nil, false
+	}
+}
+
+// analyzeTestingLogCall describes a testing Logf call. Log prints the operands
+// with Sprintln, and a single trailing newline is dropped from both, so the
+// output is the same as long as the message doesn't end with a newline.
+func analyzeTestingLogCall(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool) {
+	analyzed, ok := analyzeSprintfCall(typesInfo, call)
+	if !ok {
+		return analyzedSprintfCall{}, false
+	}
+
+	if n := len(analyzed.format.Parts); n > 0 {
+		switch last := analyzed.format.Parts[n-1].(type) {
+		case fmtparse.Literal:
+			if strings.HasSuffix(last.Text, "\n") {
+				return analyzedSprintfCall{}, false
+			}
+		case fmtparse.Directive:
+			if mayEndWithNewline(analyzed.args[len(analyzed.args)-1].transformation) {
+				return analyzedSprintfCall{}, false
+			}
+		}
+	}
+
+	return analyzed, true
+}
+
+// mayEndWithNewline reports whether the string produced by t may end with "\n".
+func mayEndWithNewline(t transform.Transformation) bool {
+	switch tt := t.(type) {
+	case transform.Literal:
+		return strings.HasSuffix(tt.Value, "\n")
+	case transform.StrConv, transform.Quote, transform.Hex, transform.Unicode:
+		return false
+	case transform.ToUpper:
+		return mayEndWithNewline(tt.Inner)
+	case transform.Prefix:
+		return mayEndWithNewline(tt.Inner)
+	case transform.Sign:
+		return mayEndWithNewline(tt.Inner)
+	case transform.Pad:
+		// Padding on the right only adds spaces.
+		return mayEndWithNewline(tt.Inner)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/fmtparse"
+)
+
+// analyzeErrorfCall describes fmt.Errorf like fmt.Sprintf. Without %w the error
+// is the same as errors.New of the formatted message.
+func analyzeErrorfCall(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool) {
+	analyzed, ok := analyzeSprintfCall(typesInfo, call)
+	if !ok {
+		return analyzedSprintfCall{}, false
+	}
+
+	for _, directive := range analyzed.format.Directives() {
+		if directive.Verb == 'w' {
+			return analyzedSprintfCall{}, false
+		}
+	}
+
+	analyzed.newError = true
+
+	return analyzed, true
+}
+
+// analyzeWrappingErrorfCall also handles the %w verbs of fmt.Errorf. The error
+// then keeps the wrapped operands around, the way fmt's own wrapError does.
+func analyzeWrappingErrorfCall(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool) {
+	analyzed, ok := analyzeFormatCall(typesInfo, call, true)
+	if !ok {
+		return analyzedSprintfCall{}, false
+	}
+
+	var indexes []int
+
+	for _, arg := range analyzed.args {
+		if arg.directive.Verb == 'w' {
+			indexes = append(indexes, arg.directive.ArgIndex)
+		}
+	}
+
+	// fmt sorts the indexes of a reordered format and wraps every operand once.
+	slices.Sort(indexes)
+	indexes = slices.Compact(indexes)
+
+	for _, index := range indexes {
+		analyzed.wrapped = append(analyzed.wrapped, wrappedOperand{
+			index: index,
+			value: analyzed.operands[index],
+		})
+	}
+
+	analyzed.newError = true
+
+	return analyzed, true
+}
+
+// analyzeSprintCall describes fmt.Sprint(a, b) as the format "%v%v", with a space
+// between the operands when neither of them is a string.
+func analyzeSprintCall(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool) {
+	separators := make([]string, len(call.Args))
+
+	for i := 1; i < len(call.Args); i++ {
+		prev, prevKnown := isStringKind(typesInfo, call.Args[i-1])
+		cur, curKnown := isStringKind(typesInfo, call.Args[i])
+
+		switch {
+		case prev || cur:
+		case prevKnown && curKnown:
+			separators[i] = " "
+		default:
+			return analyzedSprintfCall{}, false
+		}
+	}
+
+	return analyzePrintCall(typesInfo, call, separators, "")
+}
+
+// analyzeSprintlnCall describes fmt.Sprintln(a, b) as the format "%v %v\n".
+func analyzeSprintlnCall(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool) {
+	separators := make([]string, len(call.Args))
+	for i := 1; i < len(call.Args); i++ {
+		separators[i] = " "
+	}
+
+	return analyzePrintCall(typesInfo, call, separators, "\n")
+}
+
+// analyzePrintCall prints every operand with %v, preceded by its separator,
+// and suffix at the end.
+func analyzePrintCall(
+	typesInfo *types.Info,
+	call *ast.CallExpr,
+	separators []string,
+	suffix string,
+) (analyzedSprintfCall, bool) {
+	var zero analyzedSprintfCall
+
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return zero, false
+	}
+
+	var (
+		format  fmtparse.Format
+		entries []sprintfArg
+	)
+
+	addLiteral := func(text string) {
+		if text != "" {
+			format.Parts = append(format.Parts, fmtparse.Literal{Text: text})
+		}
+	}
+
+	for i, operand := range call.Args {
+		addLiteral(separators[i])
+
+		directive := fmtparse.Directive{Verb: 'v', ArgIndex: i}
+		format.Parts = append(format.Parts, directive)
+
+		t := resolveTransformation(typesInfo, operand, directive)
+		if t == nil {
+			return zero, false
+		}
+
+		entries = append(entries, sprintfArg{
+			directive:      directive,
+			transformation: t,
+			value:          operand,
+		})
+	}
+
+	addLiteral(suffix)
+
+	format.ArgCount = len(call.Args)
+
+	return analyzedSprintfCall{
+		format:   format,
+		operands: call.Args,
+		args:     entries,
+	}, true
+}
+
+// isStringKind reports whether fmt sees the operand as a string. The second result
+// is false for interfaces, as the dynamic type decides then.
+func isStringKind(typesInfo *types.Info, expr ast.Expr) (bool, bool) {
+	tv, ok := typesInfo.Types[expr]
+	if !ok || tv.Type == nil || types.IsInterface(tv.Type) {
+		return false, false
+	}
+
+	basic, ok := tv.Type.Underlying().(*types.Basic)
+
+	return ok && basic.Info()&types.IsString != 0, true
+}
@@ -2,206 +2,972 @@ package analyzer
 
 import (
 	"go/ast"
+	"go/constant"
 	"go/token"
 	"go/types"
+	"slices"
 	"strconv"
-	"unicode/utf8"
+	"strings"
 
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/fmtparse"
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/helpers"
 	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/strconvs"
 	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/transform"
 	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/knowledge"
 )
 
+// sprintfRewrite is the replacement of a Sprintf call.
+type sprintfRewrite struct {
+	expr ast.Expr
+
+	// writes replace a call used as a statement, for Fprintf.
+	writes []*ast.CallExpr
+
+	// target is the node replaced by the rewrite when it isn't the call itself,
+	// e.g. the whole sb.WriteString(fmt.Sprintf(...)).
+	target ast.Node
+
+	// temporaries hold the operands that must be evaluated once.
+	// They are declared right before stmt.
+	temporaries []*ast.AssignStmt
+	stmt        ast.Stmt
+}
+
+// callAnalyzer turns a call of one of the Sprint functions into directives
+// applied to its operands.
+type callAnalyzer func(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool)
+
+// hoister picks the operands of an analyzed call to move into temporaries.
+type hoister func(typesInfo *types.Info, operands []ast.Expr, analyzed analyzedSprintfCall) ([]int, bool)
+
 func ProcessSprintfCall(
 	typesInfo *types.Info,
-	call *ast.CallExpr,
+	cursor inspector.Cursor,
+	analyze callAnalyzer,
+	filePkgOut *packagesFileResult,
+) (sprintfRewrite, bool) {
+	var zero sprintfRewrite
+
+	analyzed, rewrite, ok := prepareSprintfCall(typesInfo, cursor, analyze, operandsToHoist, filePkgOut)
+	if !ok {
+		return zero, false
+	}
+
+	return finishSprintfCall(typesInfo, cursor, analyzed, rewrite, filePkgOut)
+}
+
+// finishSprintfCall builds the expression of a prepared rewrite, and records
+// what it needs and drops in the file.
+func finishSprintfCall(
+	typesInfo *types.Info,
+	cursor inspector.Cursor,
+	analyzed analyzedSprintfCall,
+	rewrite sprintfRewrite,
+	filePkgOut *packagesFileResult,
+) (sprintfRewrite, bool) {
+	result, deps, ok := constructResult(analyzed, filePkgOut.newDependencies())
+	if !ok || !importsVisible(typesInfo, cursor, deps) {
+		return sprintfRewrite{}, false
+	}
+
+	filePkgOut.addDependencies(deps)
+	filePkgOut.dropReferences(typesInfo, analyzed.formatExpr)
+
+	for _, index := range analyzed.droppedOperands() {
+		filePkgOut.dropOperand(typesInfo, analyzed.operands[index])
+	}
+
+	rewrite.expr = result
+
+	return rewrite, true
+}
+
+// prepareSprintfCall analyzes the call and moves the operands picked by hoist
+// into temporaries.
+func prepareSprintfCall(
+	typesInfo *types.Info,
+	cursor inspector.Cursor,
+	analyze callAnalyzer,
+	hoist hoister,
 	filePkgOut *packagesFileResult,
-) (ast.Expr, bool) {
-	analyzed, ok := analyzeSprintfCall(typesInfo, call)
+) (analyzedSprintfCall, sprintfRewrite, bool) {
+	var (
+		zero    analyzedSprintfCall
+		rewrite sprintfRewrite
+	)
+
+	call := cursor.Node().(*ast.CallExpr)
+
+	analyzed, ok := analyze(typesInfo, call)
 	if !ok {
-		return nil, false
+		return zero, rewrite, false
+	}
+
+	analyzed.foldConstants(typesInfo)
+
+	if !analyzed.canDropOperands(typesInfo, filePkgOut.varUses) ||
+		losesComments(cursor, call, keptArgs(call, analyzed)) {
+		return zero, rewrite, false
 	}
 
-	result, addedStrConv, ok := constructResult(analyzed)
+	operands := analyzed.operands
+
+	hoisted, ok := hoist(typesInfo, operands, analyzed)
 	if !ok {
-		return nil, false
+		return zero, rewrite, false
+	}
+
+	if len(hoisted) > 0 {
+		stmt, ok := findStatementForTemporaries(typesInfo, cursor)
+		if !ok {
+			return zero, rewrite, false
+		}
+
+		rewrite.stmt = stmt.Node().(ast.Stmt)
+
+		rewrite.temporaries, ok = newTemporaries(typesInfo, stmt, operands, hoisted, filePkgOut)
+		if !ok {
+			return zero, rewrite, false
+		}
+
+		for i, index := range hoisted {
+			analyzed.replaceOperand(index, rewrite.temporaries[i].Lhs[0])
+		}
 	}
 
-	filePkgOut.fmtCount--
-	if addedStrConv {
-		filePkgOut.addedStrConv = addedStrConv
+	return analyzed, rewrite, true
+}
+
+// dependencies collects what the rewritten code needs besides the call site itself.
+type dependencies struct {
+	imports []string
+	helpers []*helpers.Helper
+
+	// names are the names the imports are referred by in the file.
+	names map[string]string
+}
+
+// pkg returns the identifier referring to the package of the import path,
+// which the rewritten code then depends on.
+func (d *dependencies) pkg(path string) *ast.Ident {
+	d.addImport(path)
+
+	return &ast.Ident{Name: d.name(path)}
+}
+
+func (d *dependencies) name(path string) string {
+	if name, ok := d.names[path]; ok {
+		return name
 	}
 
-	return result, true
+	return defaultImportName(path)
+}
+
+func (d *dependencies) addImport(path string) {
+	if !slices.Contains(d.imports, path) {
+		d.imports = append(d.imports, path)
+	}
+}
+
+func (d *dependencies) addHelper(helper *helpers.Helper) {
+	if !slices.Contains(d.helpers, helper) {
+		d.helpers = append(d.helpers, helper)
+	}
 }
 
 type analyzedSprintfCall struct {
-	originalText string
-	args         []sprintfArg
+	format   fmtparse.Format
+	operands []ast.Expr
+	args     []sprintfArg // one per directive of the format, in order
+
+	// formatExpr is the constant expression of the format, if any. The rewritten
+	// code holds its value as literals instead.
+	formatExpr ast.Expr
+
+	// raw is set when the format is a raw string literal, whose pieces stay
+	// raw strings in the rewritten code.
+	raw bool
+
+	// newError wraps the result in errors.New, for fmt.Errorf.
+	newError bool
+
+	// wrapped are the operands of the %w verbs. The error keeps them
+	// instead of being created with errors.New.
+	wrapped []wrappedOperand
+}
+
+type wrappedOperand struct {
+	index int
+	value ast.Expr
+}
+
+// replaceOperand makes the directives print expr instead of the operand at index.
+func (a analyzedSprintfCall) replaceOperand(index int, expr ast.Expr) {
+	for i := range a.args {
+		if a.args[i].directive.ArgIndex == index {
+			a.args[i].value = expr
+		}
+
+		if a.args[i].directive.Width.FromArg && a.args[i].directive.Width.ArgIndex == index {
+			a.args[i].width = expr
+		}
+	}
+
+	for i := range a.wrapped {
+		if a.wrapped[i].index == index {
+			a.wrapped[i].value = expr
+		}
+	}
 }
 
 type sprintfArg struct {
-	position       [2]int
+	directive      fmtparse.Directive
 	value          ast.Expr
+	width          ast.Expr // set for '*' widths
 	transformation transform.Transformation
+
+	// folded is set when the operand is a constant, text is then what fmt
+	// prints for it.
+	folded bool
+	text   string
 }
 
 func analyzeSprintfCall(typesInfo *types.Info, call *ast.CallExpr) (analyzedSprintfCall, bool) {
-	// TODO: account for numbered placeholders (%[1]s, etc.)
-	// TODO: account for escaping
+	return analyzeFormatCall(typesInfo, call, false)
+}
 
+// analyzeFormatCall describes a call taking a format and its operands. The %w verb
+// is only valid for fmt.Errorf, allowWrap must be set then.
+func analyzeFormatCall(typesInfo *types.Info, call *ast.CallExpr, allowWrap bool) (analyzedSprintfCall, bool) {
 	var zero analyzedSprintfCall
 
-	if len(call.Args) < 1 {
+	if len(call.Args) < 1 || call.Ellipsis.IsValid() {
 		return zero, false
 	}
-	s, ok := call.Args[0].(*ast.BasicLit)
-	if !ok {
+	// Any constant works: a literal, a constant declared elsewhere, an
+	// expression of constants, of a named string type too.
+	formatExpr := call.Args[0]
+
+	tv, ok := typesInfo.Types[formatExpr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
 		return zero, false
 	}
 
 	verbArgs := call.Args[1:]
-	if len(verbArgs) == 0 {
-		// TODO: just use the string without fmt.Sprintf
 
+	format, err := fmtparse.Parse(constant.StringVal(tv.Value))
+	if err != nil {
 		return zero, false
 	}
 
-	if s.Kind != token.STRING {
-		// TODO support any expression of type string
-
+	if !format.Reordered && format.ArgCount != len(verbArgs) {
+		// fmt would report missing or extra operands in the output.
 		return zero, false
 	}
 
-	sprintfString, err := strconv.Unquote(s.Value)
-	if err != nil {
-		return zero, false
+	var entries []sprintfArg
+
+	for _, directive := range format.Directives() {
+		if directive.ArgIndex >= len(verbArgs) ||
+			directive.Width.FromArg && directive.Width.ArgIndex >= len(verbArgs) {
+			// fmt would report a bad index or a missing operand in the output.
+			return zero, false
+		}
+
+		verbArg := verbArgs[directive.ArgIndex]
+
+		var width ast.Expr
+		if directive.Width.FromArg {
+			width = verbArgs[directive.Width.ArgIndex]
+			if !isIntExpr(typesInfo, width) {
+				return zero, false
+			}
+		}
+
+		resolved := directive
+		if directive.Verb == 'w' {
+			// fmt prints the wrapped error like %v, anything else gets %!w.
+			if !allowWrap || !directive.Plain() || !implementsError(typesInfo, verbArg) {
+				return zero, false
+			}
+
+			resolved.Verb = 'v'
+		}
+
+		t := resolveTransformation(typesInfo, verbArg, resolved)
+		if directive.Verb == 'w' {
+			t = wrappedErrorTransformation(typesInfo, verbArg, t)
+		}
+		if t == nil {
+			return zero, false
+		}
+
+		entries = append(entries, sprintfArg{
+			directive:      directive,
+			transformation: t,
+			value:          verbArg,
+			width:          width,
+		})
+	}
+
+	return analyzedSprintfCall{
+		format:     format,
+		operands:   slices.Clone(verbArgs),
+		args:       entries,
+		formatExpr: formatExpr,
+		raw:        isRawString(formatExpr),
+	}, true
+}
+
+// isRawString reports whether expr is a raw string literal. The pieces of any
+// other constant are printed as interpreted literals.
+func isRawString(expr ast.Expr) bool {
+	lit, ok := ast.Unparen(expr).(*ast.BasicLit)
+
+	return ok && lit.Kind == token.STRING && lit.Value[0] == '`'
+}
+
+// wrappedErrorTransformation prints the operand of %w. An error interface may
+// be nil, fmt prints %!w(<nil>) for it then. A nil pointer would make Error()
+// panic where fmt prints <nil>, pointers are left alone.
+func wrappedErrorTransformation(
+	typesInfo *types.Info,
+	expr ast.Expr,
+	t transform.Transformation,
+) transform.Transformation {
+	switch typesInfo.TypeOf(expr).Underlying().(type) {
+	case *types.Interface:
+		return transform.WrappedError{}
+	case *types.Pointer:
+		return nil
+	default:
+		return t
+	}
+}
+
+func implementsError(typesInfo *types.Info, expr ast.Expr) bool {
+	dataType, ok := typesInfo.Types[expr]
+	if !ok || dataType.Type == nil {
+		return false
+	}
+
+	return types.Implements(dataType.Type, knowledge.Interfaces["error"])
+}
+
+func isIntExpr(typesInfo *types.Info, expr ast.Expr) bool {
+	dataType, ok := typesInfo.Types[expr]
+	if !ok || dataType.Type == nil {
+		return false
+	}
+
+	return dataType.Type.String() == "int"
+}
+
+func resolveTransformation(
+	typesInfo *types.Info,
+	arg ast.Expr,
+	directive fmtparse.Directive,
+) transform.Transformation {
+	dataType, ok := typesInfo.Types[arg]
+	if !ok {
+		return nil
+	}
+	if dataType.Type == nil {
+		return nil
+	}
+
+	if directive.Verb == 'T' {
+		// fmt prints the type before looking for any methods.
+		return resolveTransformationForTVerbOfType(typesInfo, arg, dataType.Type, directive)
+	}
+
+	if knowledge.ImplementsFormatter(dataType.Type) {
+		// fmt leaves the whole directive to the Format method.
+		return nil
+	}
+
+	if directive.Verb == 'v' {
+		if types.IsInterface(dataType.Type) && directive.Flags != (fmtparse.Flags{}) {
+			// The dynamic type may implement fmt.Formatter, e.g. the errors of
+			// github.com/pkg/errors print their stack trace for %+v.
+			return nil
+		}
+
+		// With %v the plus flag only adds field names to structs (%+v),
+		// so it changes nothing for the supported types.
+		directive.Flags.Plus = false
+
+		if directive.Flags.Sharp {
+			return resolveTransformationForSharpVVerb(dataType.Type, directive)
+		}
+	}
+
+	if directive.Width.FromArg && directive.Flags.Minus {
+		// A negative width would have to be turned into a positive one.
+		return nil
+	}
+
+	if directive.Width.Value > 1e6 {
+		// The padding helpers report such widths as invalid, like fmt does for '*'.
+		return nil
+	}
+
+	if directive.Prec.FromArg {
+		// TODO: support precision taken from operands
+		return nil
+	}
+
+	if directive.Prec.Present && !isFloatVerb(directive.Verb) {
+		// Precision truncates strings and pads integers with zeros.
+		return nil
+	}
+
+	if directive.Flags.Sharp && !isIntegerVerb(directive.Verb) && directive.Verb != 'q' && directive.Verb != 'U' {
+		// TODO: support the alternate formats of other verbs
+		return nil
+	}
+
+	switch directive.Verb {
+	case 'v':
+		return resolveTransformationForVVerb(dataType.Type, directive)
+	case 's':
+		tr := resolveTransformationForSVerb(dataType.Type)
+		if tr == nil && isByteSlice(dataType.Type) {
+			tr = transform.Wrap{Wrapper: "string"}
+		}
+
+		return withPadding(tr, directive, transform.PadWithZeros)
+	case 'd', 'o', 'O':
+		return resolveTransformationForIntegerVerb(dataType.Type, directive)
+	case 't':
+		return withPadding(resolveTransformationForTVerb(dataType.Type), directive, transform.PadWithZeros)
+	case 'x', 'X':
+		if isHexEncoded(dataType.Type) {
+			return resolveTransformationForHexVerb(dataType, directive)
+		}
+
+		fallthrough
+	case 'b':
+		if tr := resolveTransformationForIntegerVerb(dataType.Type, directive); tr != nil {
+			return tr
+		}
+
+		return resolveTransformationForFVerb(dataType.Type, directive)
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return resolveTransformationForFVerb(dataType.Type, directive)
+	case 'q':
+		return resolveTransformationForQVerb(dataType.Type, directive)
+	case 'c':
+		return withPadding(resolveTransformationForCVerb(dataType.Type), directive, transform.PadWithZeros)
+	case 'U':
+		// fmt never pads code points with zeros.
+		return withPadding(resolveTransformationForUVerb(dataType.Type, directive), directive, transform.PadWithSpaces)
+	default:
+		// TODO: support more verbs
+		return nil
+	}
+}
+
+func isFloatVerb(verb rune) bool {
+	switch verb {
+	case 'v', 'e', 'E', 'f', 'F', 'g', 'G', 'x', 'X', 'b':
+		return true
+	default:
+		return false
+	}
+}
+
+func isIntegerVerb(verb rune) bool {
+	switch verb {
+	case 'v', 'd', 'b', 'o', 'O', 'x', 'X':
+		return true
+	default:
+		return false
+	}
+}
+
+func implementsStringMethods(t types.Type) bool {
+	return types.Implements(t, knowledge.Interfaces["error"]) ||
+		types.Implements(t, knowledge.Interfaces["fmt.Stringer"])
+}
+
+// isHexEncoded reports whether fmt prints the value with %x byte by byte
+// rather than as a number.
+func isHexEncoded(t types.Type) bool {
+	return implementsStringMethods(t) ||
+		t.Underlying().String() == "string" ||
+		isByteSlice(t) ||
+		isByteArray(t)
+}
+
+// resolveTransformationForHexVerb encodes the result of Error() and String()
+// first, then strings, byte slices and byte arrays.
+func resolveTransformationForHexVerb(
+	dataType types.TypeAndValue,
+	directive fmtparse.Directive,
+) transform.Transformation {
+	if directive.Prec.Present {
+		// TODO: support the number of bytes to encode (%.4x)
+		return nil
+	}
+
+	hexTr := transform.Hex{Space: directive.Flags.Space}
+	if directive.Flags.Sharp {
+		hexTr.Prefix = "0x"
+	}
+
+	t := dataType.Type
+
+	switch {
+	case isByteSlice(t) && !implementsStringMethods(t):
+		hexTr.Inner = transform.NoOp{}
+	case isByteArray(t) && !implementsStringMethods(t):
+		hexTr.Inner = transform.SliceArray{
+			Len:         t.Underlying().(*types.Array).Len(),
+			Addressable: dataType.Addressable(),
+		}
+	default:
+		hexTr.Inner = resolveTransformationForSVerb(t)
+		if hexTr.Inner == nil {
+			return nil
+		}
+
+		hexTr.FromString = true
+	}
+
+	var res transform.Transformation = hexTr
+
+	if directive.Verb == 'X' {
+		res = transform.ToUpper{Inner: res}
+	}
+
+	return withPadding(res, directive, transform.PadWithZeros)
+}
+
+// resolveTransformationForVVerb follows the order of fmt: the error and fmt.Stringer
+// interfaces come first, then the default format of the underlying kind.
+func resolveTransformationForVVerb(t types.Type, directive fmtparse.Directive) transform.Transformation {
+	if directive.Prec.Present {
+		return resolveTransformationForFVerb(t, directive)
+	}
+
+	if tr := resolveTransformationForSVerb(t); tr != nil {
+		return withPadding(tr, directive, transform.PadWithZeros)
+	}
+
+	if tr := resolveTransformationForIntegerVerb(t, directive); tr != nil {
+		return tr
+	}
+
+	if tr := resolveTransformationForTVerb(t); tr != nil {
+		return withPadding(tr, directive, transform.PadWithZeros)
+	}
+
+	return resolveTransformationForFVerb(t, directive)
+}
+
+// resolveTransformationForTVerbOfType replaces the operand with the name of its
+// type when the type is known at compile time. The operand must then be one
+// that can be dropped, see canDropOperands.
+func resolveTransformationForTVerbOfType(
+	typesInfo *types.Info,
+	arg ast.Expr,
+	t types.Type,
+	directive fmtparse.Directive,
+) transform.Transformation {
+	if types.IsInterface(t) {
+		// The dynamic type is printed.
+		return nil
+	}
+
+	if directive.Prec.Present || directive.Prec.FromArg {
+		// Precision truncates the name.
+		return nil
+	}
+
+	if directive.Width.FromArg && directive.Flags.Minus || directive.Width.Value > 1e6 {
+		return nil
+	}
+
+	name, ok := reflectTypeString(t)
+	if !ok {
+		return nil
+	}
+
+	return withPadding(transform.Literal{Value: name}, directive, transform.PadWithZeros)
+}
+
+// resolveTransformationForSharpVVerb prints the result of GoString() like %s would,
+// or the Go-syntax representation of basic values.
+func resolveTransformationForSharpVVerb(t types.Type, directive fmtparse.Directive) transform.Transformation {
+	if types.Implements(t, knowledge.Interfaces["fmt.GoStringer"]) {
+		if directive.Prec.Present {
+			// Precision truncates the result.
+			return nil
+		}
+
+		return withPadding(transform.CallGoStringMethod{}, directive, transform.PadWithZeros)
+	}
+
+	// The Go syntax doesn't depend on String() and Error().
+	directive.Flags.Sharp = false
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		// TODO: support the Go-syntax representation of composite types
+		return nil
+	}
+
+	switch {
+	case basic.Info()&types.IsFloat != 0:
+		// Floats are printed like with %v.
+		return resolveTransformationForFVerb(t, directive)
+	case directive.Prec.Present:
+		return nil
+	case basic.Info()&types.IsString != 0:
+		var tr transform.Transformation = transform.NoOp{}
+		if t.String() != "string" {
+			tr = transform.Wrap{Wrapper: "string"}
+		}
+
+		return withPadding(transform.Quote{Inner: tr}, directive, transform.PadWithZeros)
+	case basic.Info()&types.IsBoolean != 0:
+		return withPadding(resolveTransformationForTVerb(t), directive, transform.PadWithZeros)
+	case basic.Info()&types.IsUnsigned != 0:
+		// Unsigned integers are printed in hex with the 0x prefix.
+		directive.Verb = 'x'
+		directive.Flags.Sharp = true
+
+		return resolveTransformationForIntegerVerb(t, directive)
+	case basic.Info()&types.IsInteger != 0:
+		directive.Verb = 'd'
+
+		return resolveTransformationForIntegerVerb(t, directive)
+	default:
+		return nil
+	}
+}
+
+// withSign applies the '+' and ' ' flags to a formatted number.
+func withSign(tr transform.Transformation, directive fmtparse.Directive, signed bool) transform.Transformation {
+	switch {
+	case directive.Flags.Plus:
+		return transform.Sign{Inner: tr, Sign: '+', Signed: signed}
+	case directive.Flags.Space:
+		return transform.Sign{Inner: tr, Sign: ' ', Signed: signed}
+	default:
+		return tr
+	}
+}
+
+// withPadding applies the width and the '-' and '0' flags. The zeros padding
+// is used for the '0' flag.
+func withPadding(
+	tr transform.Transformation,
+	directive fmtparse.Directive,
+	zeros transform.Padding,
+) transform.Transformation {
+	if tr == nil {
+		return nil
+	}
+
+	width := directive.Width
+	if !width.FromArg && width.Value == 0 {
+		return tr
+	}
+
+	pad := transform.Pad{
+		Inner:        tr,
+		Padding:      transform.PadWithSpaces,
+		Width:        width.Value,
+		WidthFromArg: width.FromArg,
+	}
+
+	switch {
+	case directive.Flags.Minus:
+		pad.Width = -pad.Width
+	case directive.Flags.Zero:
+		pad.Padding = zeros
+	}
+
+	return pad
+}
+
+func resolveTransformationForSVerb(t types.Type) transform.Transformation {
+	if types.Implements(t, knowledge.Interfaces["error"]) {
+		return transform.CallErrorMethod{}
+	}
+
+	if types.Implements(t, knowledge.Interfaces["fmt.Stringer"]) {
+		return transform.CallStringMethod{}
+	}
+
+	if t.String() == "string" {
+		return transform.NoOp{}
+	}
+
+	if t.Underlying().String() == "string" {
+		return transform.Wrap{Wrapper: "string"}
+	}
+
+	return nil
+}
+
+// resolveTransformationForQVerb quotes the result of Error() and String() first,
+// then strings and byte slices, then integers as runes.
+func resolveTransformationForQVerb(t types.Type, directive fmtparse.Directive) transform.Transformation {
+	tr := resolveTransformationForSVerb(t)
+	if tr == nil && isByteSlice(t) {
+		tr = transform.Wrap{Wrapper: "string"}
+	}
+
+	if tr != nil {
+		tr = transform.Quote{
+			Inner:     tr,
+			ASCII:     directive.Flags.Plus,
+			Backquote: directive.Flags.Sharp,
+		}
+
+		return withPadding(tr, directive, transform.PadWithZeros)
+	}
+
+	op := resolveQuoteRuneOp(t, directive.Flags.Plus)
+	if op == nil {
+		return nil
+	}
+
+	return withPadding(transform.StrConv{Op: op}, directive, transform.PadWithZeros)
+}
+
+func isByteSlice(t types.Type) bool {
+	slice, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+
+	return types.Identical(slice.Elem(), types.Typ[types.Byte])
+}
+
+func isByteArray(t types.Type) bool {
+	array, ok := t.Underlying().(*types.Array)
+	if !ok {
+		return false
+	}
+
+	return types.Identical(array.Elem(), types.Typ[types.Byte])
+}
+
+func resolveQuoteRuneOp(t types.Type, ascii bool) strconvs.Op {
+	castToRune, clamp, ok := resolveRuneConversion(t)
+	if !ok {
+		return nil
 	}
 
-	var entries []sprintfArg
-	var verbBuf []rune
-
-	for i, r := range sprintfString {
-		if r == '%' {
-			verbBuf = verbBuf[:0]
-			verbBuf = append(verbBuf, '%')
-		} else if len(verbBuf) > 0 {
-			verbBuf = append(verbBuf, r)
-		} else {
-			continue
-		}
+	return strconvs.QuoteRune{ASCII: ascii, CastToRune: castToRune, Clamp: clamp}
+}
 
-		if isVerb(string(verbBuf)) {
-			if len(entries) >= len(verbArgs) {
-				return zero, false
-			}
+// resolveRuneConversion tells how to turn an integer into the rune fmt prints for it.
+// fmt prints utf8.RuneError for values above utf8.MaxRune, so the types
+// wider than a rune have to be clamped.
+func resolveRuneConversion(t types.Type) (castToRune, clamp, ok bool) {
+	basic, isBasic := t.Underlying().(*types.Basic)
+	if !isBasic {
+		return false, false, false
+	}
 
-			verbArg := verbArgs[len(entries)]
+	switch basic.Kind() {
+	case types.Int32:
+		return !types.Identical(t, types.Typ[types.Int32]), false, true
+	case types.Int16, types.Int8, types.Uint16, types.Uint8:
+		return true, false, true
+	case types.Int, types.Int64, types.Uint, types.Uint64, types.Uint32, types.Uintptr:
+		return false, true, true
+	default:
+		return false, false, false
+	}
+}
 
-			t := resolveTransformation(typesInfo, verbArg, string(verbBuf))
-			if t == nil {
-				return zero, false
-			}
+func resolveTransformationForCVerb(t types.Type) transform.Transformation {
+	castToRune, clamp, ok := resolveRuneConversion(t)
+	if !ok {
+		return nil
+	}
 
-			entries = append(entries, sprintfArg{
-				position:       [2]int{i - len(verbBuf) + 1, i + 1},
-				transformation: t,
-				value:          verbArg,
-			})
+	return transform.Char{CastToRune: castToRune, Clamp: clamp}
+}
 
-			verbBuf = verbBuf[:0]
-		}
+func resolveTransformationForUVerb(t types.Type, directive fmtparse.Directive) transform.Transformation {
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 {
+		return nil
 	}
 
-	return analyzedSprintfCall{
-		originalText: sprintfString,
-		args:         entries,
-	}, true
+	return transform.Unicode{
+		WithChar:     directive.Flags.Sharp,
+		CastToUint64: !types.Identical(t, types.Typ[types.Uint64]),
+	}
 }
 
-func resolveTransformation(typesInfo *types.Info, arg ast.Expr, verb string) transform.Transformation {
-	dataType, ok := typesInfo.Types[arg]
-	if !ok {
+func resolveTransformationForIntegerVerb(t types.Type, directive fmtparse.Directive) transform.Transformation {
+	if directive.Prec.Present {
+		// TODO: support the minimum number of digits (%.3d)
 		return nil
 	}
-	if dataType.Type == nil {
+
+	base, prefixes, ok := getBaseAndPrefixesFromDirective(directive)
+	if !ok {
 		return nil
 	}
 
-	switch verb {
-	case "%s":
-		return resolveTransformationForSVerb(dataType.Type)
-	case "%d":
-		return resolveTransformationForDVerb(dataType.Type)
-	case "%f":
-		return resolveTransformationForFVerb(dataType.Type, verb)
-	default:
-		// TODO: support more verbs
+	op, signed := resolveIntegerOp(t, base)
+	if op == nil {
 		return nil
 	}
-}
 
-func resolveTransformationForSVerb(t types.Type) transform.Transformation {
-	if types.Implements(t, knowledge.Interfaces["error"]) {
-		return transform.CallErrorMethod{}
+	var res transform.Transformation = transform.StrConv{Op: op}
+
+	if directive.Verb == 'X' {
+		res = transform.ToUpper{Inner: res}
 	}
 
-	if types.Implements(t, knowledge.Interfaces["fmt.Stringer"]) {
-		return transform.CallStringMethod{}
+	res = withSign(res, directive, signed)
+
+	// fmt puts the zeros between the prefix and the digits, and they don't
+	// count the prefix in, so the padding goes first.
+	zeroPadded := directive.Flags.Zero && !directive.Flags.Minus
+	if zeroPadded {
+		if directive.Width.FromArg && len(prefixes) > 0 {
+			// A negative width would pad with spaces after the prefix.
+			return nil
+		}
+
+		res = withPadding(res, directive, transform.PadNumberWithZeros)
 	}
 
-	if t.String() == "string" {
-		return transform.NoOp{}
+	for _, prefix := range prefixes {
+		res = transform.Prefix{Inner: res, Prefix: prefix, Signed: signed || directive.Flags.Plus || directive.Flags.Space}
 	}
 
-	if t.Underlying().String() == "string" {
-		return transform.Wrap{Wrapper: "string"}
+	if !zeroPadded {
+		res = withPadding(res, directive, transform.PadWithSpaces)
 	}
 
-	return nil
+	return res
+}
+
+// getBaseAndPrefixesFromDirective returns the base of the verb and the prefixes
+// fmt adds after the sign, innermost first.
+func getBaseAndPrefixesFromDirective(directive fmtparse.Directive) (int, []string, bool) {
+	var (
+		base     int
+		prefixes []string
+	)
+
+	switch directive.Verb {
+	case 'v', 'd':
+		return 10, nil, true
+	case 'b':
+		base = 2
+		if directive.Flags.Sharp {
+			prefixes = append(prefixes, "0b")
+		}
+	case 'o', 'O':
+		base = 8
+		if directive.Flags.Sharp {
+			prefixes = append(prefixes, "0")
+		}
+		if directive.Verb == 'O' {
+			prefixes = append(prefixes, "0o")
+		}
+	case 'x':
+		base = 16
+		if directive.Flags.Sharp {
+			prefixes = append(prefixes, "0x")
+		}
+	case 'X':
+		base = 16
+		if directive.Flags.Sharp {
+			prefixes = append(prefixes, "0X")
+		}
+	default:
+		return 0, nil, false
+	}
+
+	return base, prefixes, true
 }
 
-func resolveTransformationForDVerb(t types.Type) transform.Transformation {
+// resolveIntegerOp also reports whether the integer is signed.
+func resolveIntegerOp(t types.Type, base int) (strconvs.Op, bool) {
 	switch t.String() {
 	case "int":
-		return transform.StrConv{Op: strconvs.Itoa{}}
+		if base == 10 {
+			return strconvs.Itoa{}, true
+		}
+
+		return strconvs.FormatInt{Base: base, CastToInt64: true}, true
 	case "int64":
-		return transform.StrConv{Op: strconvs.FormatInt{}}
-	case "int32", "int16", "int8":
-		return transform.StrConv{Op: strconvs.FormatInt{CastToInt64: true}}
+		return strconvs.FormatInt{Base: base}, true
+	case "int32", "int16", "int8", "rune":
+		return strconvs.FormatInt{Base: base, CastToInt64: true}, true
 	case "uint64":
-		return transform.StrConv{Op: strconvs.FormatUint{}}
-	case "uint32", "uint16", "uint8", "uint":
-		return transform.StrConv{Op: strconvs.FormatUint{CastToUint64: true}}
+		return strconvs.FormatUint{Base: base}, false
+	case "uint32", "uint16", "uint8", "uint", "byte":
+		return strconvs.FormatUint{Base: base, CastToUint64: true}, false
 	}
 
 	switch t.Underlying().String() {
 	case "int":
-		return transform.StrConv{Op: strconvs.Itoa{CastToInt: true}}
-	case "int64":
-		return transform.StrConv{Op: strconvs.FormatInt{CastToInt64: true}}
-	case "int32", "int16", "int8":
-		return transform.StrConv{Op: strconvs.FormatInt{CastToInt64: true}}
-	case "uint64":
-		return transform.StrConv{Op: strconvs.FormatUint{CastToUint64: true}}
-	case "uint32", "uint16", "uint8", "uint":
-		return transform.StrConv{Op: strconvs.FormatUint{CastToUint64: true}}
+		if base == 10 {
+			return strconvs.Itoa{CastToInt: true}, true
+		}
+
+		return strconvs.FormatInt{Base: base, CastToInt64: true}, true
+	case "int64", "int32", "int16", "int8", "rune":
+		return strconvs.FormatInt{Base: base, CastToInt64: true}, true
+	case "uint64", "uint32", "uint16", "uint8", "uint", "byte":
+		return strconvs.FormatUint{Base: base, CastToUint64: true}, false
+	}
+
+	return nil, false
+}
+
+func resolveTransformationForTVerb(t types.Type) transform.Transformation {
+	if t.String() == "bool" {
+		return transform.StrConv{Op: strconvs.FormatBool{}}
+	}
+
+	if t.Underlying().String() == "bool" {
+		return transform.StrConv{Op: strconvs.FormatBool{CastToBool: true}}
 	}
 
 	return nil
 }
 
-func resolveTransformationForFVerb(t types.Type, verb string) transform.Transformation {
-	var castToFloat64 bool
+func resolveTransformationForFVerb(t types.Type, directive fmtparse.Directive) transform.Transformation {
+	if directive.Flags.Sharp {
+		// TODO: support keeping the decimal point and trailing zeros (%#g)
+		return nil
+	}
+
+	var (
+		castToFloat64 bool
+		bitSize       int
+	)
 
 	switch t.String() {
 	case "float64":
+		bitSize = 64
 	case "float32":
 		castToFloat64 = true
+		bitSize = 32
 	default:
 		switch t.Underlying().String() {
 		case "float64":
+			bitSize = 64
 		case "float32":
+			bitSize = 32
 		default:
 			return nil
 		}
@@ -209,84 +975,215 @@ func resolveTransformationForFVerb(t types.Type, verb string) transform.Transfor
 		castToFloat64 = true
 	}
 
-	fmt, prec, ok := getFmtAndPrecFromVerb(verb)
+	fmt, prec, ok := getFmtAndPrecFromDirective(directive)
 	if !ok {
 		return nil
 	}
 
-	return transform.StrConv{Op: strconvs.FormatFloat{
+	var res transform.Transformation = transform.StrConv{Op: strconvs.FormatFloat{
 		Fmt:           fmt,
 		Prec:          prec,
+		BitSize:       bitSize,
 		CastToFloat64: castToFloat64,
 	}}
+
+	res = withSign(res, directive, true)
+
+	return withPadding(res, directive, transform.PadNumberWithZeros)
 }
 
-func getFmtAndPrecFromVerb(verb string) (byte, int, bool) {
-	switch verb {
-	case "%f":
+// getFmtAndPrecFromDirective mirrors the default precisions of fmt.
+func getFmtAndPrecFromDirective(directive fmtparse.Directive) (byte, int, bool) {
+	var (
+		fmt  byte
+		prec int
+	)
+
+	switch directive.Verb {
+	case 'v':
+		// fmt prints floats with %g and the shortest representation for %v.
+		fmt, prec = 'g', -1
+	case 'b', 'g', 'G', 'x', 'X':
 		// The special precision -1 uses the smallest number of digits necessary
 		// such that ParseFloat will return f exactly.
-		return 'f', -1, true
-	// TODO: parse more floating-point verbs
+		fmt, prec = byte(directive.Verb), -1
+	case 'f', 'e', 'E':
+		fmt, prec = byte(directive.Verb), 6
+	case 'F':
+		// strconv has no 'F' format, for fmt it is a synonym for %f.
+		fmt, prec = 'f', 6
 	default:
 		return 0, 0, false
 	}
+
+	if directive.Prec.Present {
+		prec = directive.Prec.Value
+	}
+
+	return fmt, prec, true
 }
 
-func constructResult(analyzed analyzedSprintfCall) (ast.Expr, bool, bool) {
-	if len(analyzed.args) == 0 {
-		return nil, false, false
+func constructResult(analyzed analyzedSprintfCall, deps dependencies) (ast.Expr, dependencies, bool) {
+	var (
+		exprs    []ast.Expr
+		text     strings.Builder // the pieces known since the last expression
+		argIndex int
+	)
+
+	flush := func() {
+		if text.Len() > 0 {
+			exprs = append(exprs, newStringLit(text.String(), analyzed.raw))
+			text.Reset()
+		}
 	}
 
-	if len(analyzed.args) == 1 {
-		arg := analyzed.args[0]
+	// The literals of a transformed value, like the sign of %+d, join the
+	// pieces around it.
+	var add func(e ast.Expr)
+	add = func(e ast.Expr) {
+		if sum, ok := e.(*ast.BinaryExpr); ok && sum.Op == token.ADD {
+			add(sum.X)
+			add(sum.Y)
+
+			return
+		}
+
+		if lit, ok := e.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if value, err := strconv.Unquote(lit.Value); err == nil {
+				text.WriteString(value)
+				return
+			}
+		}
+
+		flush()
+		exprs = append(exprs, e)
+	}
 
-		head := analyzed.originalText[:arg.position[0]]
-		tail := analyzed.originalText[arg.position[1]:]
+	for _, part := range analyzed.format.Parts {
+		switch p := part.(type) {
+		case fmtparse.Literal:
+			text.WriteString(p.Text)
+		case fmtparse.Directive:
+			arg := analyzed.args[argIndex]
+			argIndex++
+
+			if arg.folded {
+				text.WriteString(arg.text)
+				continue
+			}
 
-		if head == "" && tail == "" {
-			newVal, addedStrConv := transformValue(arg.value, arg.transformation)
+			if literal, ok := arg.transformation.(transform.Literal); ok {
+				text.WriteString(literal.Value)
+				continue
+			}
 
-			return newVal, addedStrConv, true
+			add(transformValue(arg, arg.transformation, &deps))
 		}
 	}
 
-	res := &ast.BinaryExpr{
-		Op: token.ADD,
+	flush()
+
+	var res ast.Expr
+
+	switch len(exprs) {
+	case 0:
+		res = &ast.BasicLit{Kind: token.STRING, Value: `""`}
+	case 1:
+		res = exprs[0]
+	default:
+		sum := &ast.BinaryExpr{
+			Op: token.ADD,
+		}
+
+		for _, e := range exprs {
+			sum = addExprToSum(sum, e)
+		}
+
+		res = sum
 	}
 
-	var (
-		cursor       int
-		addedStrConv bool
-	)
+	if len(analyzed.wrapped) > 0 {
+		return newWrappingError(res, analyzed.wrapped, &deps), deps, true
+	}
 
-	for _, arg := range analyzed.args {
-		head := analyzed.originalText[cursor:arg.position[0]]
-		if head != "" {
-			res = addExprToSum(res, &ast.BasicLit{
-				Kind:  token.STRING,
-				Value: strconv.Quote(head),
-			})
+	if analyzed.newError {
+		res = &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   deps.pkg("errors"),
+				Sel: &ast.Ident{Name: "New"},
+			},
+			Args: []ast.Expr{res},
 		}
+	}
 
-		newValueExpr, strConv := transformValue(arg.value, arg.transformation)
-		if strConv {
-			addedStrConv = true
+	return res, deps, true
+}
+
+// newStringLit returns the literal holding text, as a raw string when raw is
+// set and text fits in one. A backquote can't appear in a raw string, it gets
+// an interpreted literal of its own in between.
+func newStringLit(text string, raw bool) ast.Expr {
+	if !raw || text == "" || !strconv.CanBackquote(strings.NewReplacer("\n", "", "`", "").Replace(text)) {
+		return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(text)}
+	}
+
+	var res ast.Expr
+
+	add := func(lit *ast.BasicLit) {
+		if res == nil {
+			res = lit
+		} else {
+			res = &ast.BinaryExpr{X: res, Op: token.ADD, Y: lit}
 		}
+	}
 
-		res = addExprToSum(res, newValueExpr)
+	for i, piece := range strings.Split(text, "`") {
+		if i > 0 {
+			add(&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("`")})
+		}
 
-		cursor = arg.position[1]
+		if piece != "" {
+			add(&ast.BasicLit{Kind: token.STRING, Value: "`" + piece + "`"})
+		}
 	}
 
-	if cursor < utf8.RuneCountInString(analyzed.originalText) {
-		res = addExprToSum(res, &ast.BasicLit{
-			Kind:  token.STRING,
-			Value: strconv.Quote(analyzed.originalText[cursor:]),
-		})
+	return res
+}
+
+// newWrappingError builds the error fmt.Errorf returns for %w: a single wrapped
+// error is unwrapped with errors.Unwrap, several ones are matched by errors.Is
+// and errors.As only.
+func newWrappingError(msg ast.Expr, wrapped []wrappedOperand, deps *dependencies) ast.Expr {
+	var (
+		helper = helpers.WrapError
+		field  = &ast.KeyValueExpr{Key: &ast.Ident{Name: "err"}, Value: wrapped[0].value}
+	)
+
+	if len(wrapped) > 1 {
+		errs := &ast.CompositeLit{
+			Type: &ast.ArrayType{Elt: &ast.Ident{Name: "error"}},
+		}
+
+		for _, w := range wrapped {
+			errs.Elts = append(errs.Elts, w.value)
+		}
+
+		helper = helpers.WrapErrors
+		field = &ast.KeyValueExpr{Key: &ast.Ident{Name: "errs"}, Value: errs}
 	}
 
-	return res, addedStrConv, true
+	deps.addHelper(helper)
+
+	return &ast.UnaryExpr{
+		Op: token.AND,
+		X: &ast.CompositeLit{
+			Type: &ast.Ident{Name: helper.Name},
+			Elts: []ast.Expr{
+				&ast.KeyValueExpr{Key: &ast.Ident{Name: "msg"}, Value: msg},
+				field,
+			},
+		},
+	}
 }
 
 func addExprToSum(base *ast.BinaryExpr, e ast.Expr) *ast.BinaryExpr {
@@ -301,26 +1198,50 @@ func addExprToSum(base *ast.BinaryExpr, e ast.Expr) *ast.BinaryExpr {
 	return base
 }
 
-func transformValue(value ast.Expr, t transform.Transformation) (ast.Expr, bool) {
+func transformValue(arg sprintfArg, t transform.Transformation, deps *dependencies) ast.Expr {
+	value := arg.value
+
 	switch tt := t.(type) {
 	case transform.NoOp:
-		return value, false
+		return value
+	case transform.Literal:
+		return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(tt.Value)}
 	case transform.CallStringMethod:
-		return transformValueToCallStringMethod(value), false
+		return transformValueToCallStringMethod(value)
 	case transform.CallErrorMethod:
-		return transformValueToCallErrorMethod(value), false
+		return transformValueToCallErrorMethod(value)
+	case transform.WrappedError:
+		return transformValueToWrappedError(value, deps)
+	case transform.CallGoStringMethod:
+		return transformValueToCallGoStringMethod(value)
 	case transform.Wrap:
-		return transformValueWithWrap(value, tt), false
+		return transformValueWithWrap(value, tt)
 	case transform.StrConv:
-		return transformValueWithStrConv(value, tt), true
+		return transformValueWithStrConv(value, tt, deps)
+	case transform.ToUpper:
+		return transformValueWithToUpper(transformValue(arg, tt.Inner, deps), deps)
+	case transform.Prefix:
+		return transformValueWithPrefix(transformValue(arg, tt.Inner, deps), tt, deps)
+	case transform.Sign:
+		return transformValueWithSign(transformValue(arg, tt.Inner, deps), tt, deps)
+	case transform.Pad:
+		return transformValueWithPad(transformValue(arg, tt.Inner, deps), arg.width, tt, deps)
+	case transform.Quote:
+		return transformValueWithQuote(transformValue(arg, tt.Inner, deps), tt, deps)
+	case transform.Char:
+		return transformValueWithChar(value, tt, deps)
+	case transform.Unicode:
+		return transformValueWithUnicode(value, tt, deps)
+	case transform.SliceArray:
+		return transformValueWithSliceArray(value, tt)
+	case transform.Hex:
+		return transformValueWithHex(transformValue(arg, tt.Inner, deps), tt, deps)
 	default:
 		panic("unknown transformation")
 	}
 }
 
-func transformValueWithStrConv(value ast.Expr, tStrConv transform.StrConv) ast.Expr {
-	// TODO: point to actual strconv object? or at least dedupe strconv-ident pointers?
-
+func transformValueWithStrConv(value ast.Expr, tStrConv transform.StrConv, deps *dependencies) ast.Expr {
 	switch op := tStrConv.Op.(type) {
 
 	case strconvs.Itoa:
@@ -330,7 +1251,7 @@ func transformValueWithStrConv(value ast.Expr, tStrConv transform.StrConv) ast.E
 
 		return &ast.CallExpr{
 			Fun: &ast.SelectorExpr{
-				X:   &ast.Ident{Name: "strconv"},
+				X:   deps.pkg("strconv"),
 				Sel: &ast.Ident{Name: "Itoa"},
 			},
 			Args: []ast.Expr{value},
@@ -343,10 +1264,10 @@ func transformValueWithStrConv(value ast.Expr, tStrConv transform.StrConv) ast.E
 
 		return &ast.CallExpr{
 			Fun: &ast.SelectorExpr{
-				X:   &ast.Ident{Name: "strconv"},
+				X:   deps.pkg("strconv"),
 				Sel: &ast.Ident{Name: "FormatInt"},
 			},
-			Args: []ast.Expr{value, &ast.BasicLit{Value: "10", Kind: token.INT}},
+			Args: []ast.Expr{value, baseLit(op.Base)},
 		}
 
 	case strconvs.FormatUint:
@@ -356,10 +1277,39 @@ func transformValueWithStrConv(value ast.Expr, tStrConv transform.StrConv) ast.E
 
 		return &ast.CallExpr{
 			Fun: &ast.SelectorExpr{
-				X:   &ast.Ident{Name: "strconv"},
+				X:   deps.pkg("strconv"),
 				Sel: &ast.Ident{Name: "FormatUint"},
 			},
-			Args: []ast.Expr{value, &ast.BasicLit{Value: "10", Kind: token.INT}},
+			Args: []ast.Expr{value, baseLit(op.Base)},
+		}
+
+	case strconvs.FormatBool:
+		if op.CastToBool {
+			value = &ast.CallExpr{Fun: &ast.Ident{Name: "bool"}, Args: []ast.Expr{value}}
+		}
+
+		return &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   deps.pkg("strconv"),
+				Sel: &ast.Ident{Name: "FormatBool"},
+			},
+			Args: []ast.Expr{value},
+		}
+
+	case strconvs.QuoteRune:
+		value = toRune(value, op.CastToRune, op.Clamp, deps)
+
+		name := "QuoteRune"
+		if op.ASCII {
+			name = "QuoteRuneToASCII"
+		}
+
+		return &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   deps.pkg("strconv"),
+				Sel: &ast.Ident{Name: name},
+			},
+			Args: []ast.Expr{value},
 		}
 
 	case strconvs.FormatFloat:
@@ -370,14 +1320,14 @@ func transformValueWithStrConv(value ast.Expr, tStrConv transform.StrConv) ast.E
 
 		return &ast.CallExpr{
 			Fun: &ast.SelectorExpr{
-				X:   &ast.Ident{Name: "strconv"},
+				X:   deps.pkg("strconv"),
 				Sel: &ast.Ident{Name: "FormatFloat"},
 			},
 			Args: []ast.Expr{
 				val,
 				&ast.BasicLit{Value: strconv.QuoteRune(rune(op.Fmt)), Kind: token.CHAR},
 				&ast.BasicLit{Value: strconv.Itoa(op.Prec), Kind: token.INT},
-				&ast.BasicLit{Value: "64", Kind: token.INT},
+				&ast.BasicLit{Value: strconv.Itoa(op.BitSize), Kind: token.INT},
 			},
 		}
 
@@ -386,6 +1336,198 @@ func transformValueWithStrConv(value ast.Expr, tStrConv transform.StrConv) ast.E
 	}
 }
 
+func toRune(value ast.Expr, castToRune, clamp bool, deps *dependencies) ast.Expr {
+	switch {
+	case clamp:
+		deps.addHelper(helpers.Rune)
+
+		return &ast.CallExpr{
+			Fun:  &ast.Ident{Name: helpers.Rune.Name},
+			Args: []ast.Expr{&ast.CallExpr{Fun: &ast.Ident{Name: "uint64"}, Args: []ast.Expr{value}}},
+		}
+	case castToRune:
+		return &ast.CallExpr{Fun: &ast.Ident{Name: "rune"}, Args: []ast.Expr{value}}
+	default:
+		return value
+	}
+}
+
+func baseLit(base int) *ast.BasicLit {
+	if base == 0 {
+		base = 10
+	}
+
+	return &ast.BasicLit{Value: strconv.Itoa(base), Kind: token.INT}
+}
+
+func transformValueWithToUpper(value ast.Expr, deps *dependencies) ast.Expr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   deps.pkg("strings"),
+			Sel: &ast.Ident{Name: "ToUpper"},
+		},
+		Args: []ast.Expr{value},
+	}
+}
+
+func transformValueWithPrefix(value ast.Expr, prefix transform.Prefix, deps *dependencies) ast.Expr {
+	prefixLit := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(prefix.Prefix)}
+
+	if !prefix.Signed && prefix.Prefix != "0" {
+		return &ast.BinaryExpr{X: prefixLit, Op: token.ADD, Y: value}
+	}
+
+	// The prefix goes after the sign, and octal zero is not doubled.
+	deps.addHelper(helpers.Prefix)
+
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: helpers.Prefix.Name},
+		Args: []ast.Expr{value, prefixLit},
+	}
+}
+
+func transformValueWithSign(value ast.Expr, sign transform.Sign, deps *dependencies) ast.Expr {
+	signLit := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(string(sign.Sign))}
+
+	if !sign.Signed {
+		return &ast.BinaryExpr{X: signLit, Op: token.ADD, Y: value}
+	}
+
+	deps.addHelper(helpers.Sign)
+
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: helpers.Sign.Name},
+		Args: []ast.Expr{value, signLit},
+	}
+}
+
+func transformValueWithPad(value ast.Expr, width ast.Expr, pad transform.Pad, deps *dependencies) ast.Expr {
+	var helper *helpers.Helper
+
+	switch pad.Padding {
+	case transform.PadWithSpaces:
+		helper = helpers.Pad
+	case transform.PadWithZeros:
+		helper = helpers.PadZeros
+	case transform.PadNumberWithZeros:
+		helper = helpers.PadNumber
+	default:
+		panic("unknown padding")
+	}
+
+	deps.addHelper(helper)
+
+	if !pad.WidthFromArg {
+		width = &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(pad.Width)}
+	}
+
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: helper.Name},
+		Args: []ast.Expr{value, width},
+	}
+}
+
+func transformValueWithQuote(value ast.Expr, quote transform.Quote, deps *dependencies) ast.Expr {
+	quoteFunc := &ast.SelectorExpr{
+		X:   deps.pkg("strconv"),
+		Sel: &ast.Ident{Name: "Quote"},
+	}
+	if quote.ASCII {
+		quoteFunc.Sel.Name = "QuoteToASCII"
+	}
+
+	if !quote.Backquote {
+		return &ast.CallExpr{Fun: quoteFunc, Args: []ast.Expr{value}}
+	}
+
+	deps.addHelper(helpers.Backquote)
+
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: helpers.Backquote.Name},
+		Args: []ast.Expr{value, quoteFunc},
+	}
+}
+
+func transformValueWithChar(value ast.Expr, char transform.Char, deps *dependencies) ast.Expr {
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: "string"},
+		Args: []ast.Expr{toRune(value, char.CastToRune, char.Clamp, deps)},
+	}
+}
+
+func transformValueWithUnicode(value ast.Expr, unicode transform.Unicode, deps *dependencies) ast.Expr {
+	deps.addHelper(helpers.Unicode)
+
+	if unicode.CastToUint64 {
+		value = &ast.CallExpr{Fun: &ast.Ident{Name: "uint64"}, Args: []ast.Expr{value}}
+	}
+
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: helpers.Unicode.Name},
+		Args: []ast.Expr{value, &ast.Ident{Name: strconv.FormatBool(unicode.WithChar)}},
+	}
+}
+
+func transformValueWithSliceArray(value ast.Expr, slice transform.SliceArray) ast.Expr {
+	if slice.Addressable {
+		return &ast.SliceExpr{X: value}
+	}
+
+	// func(a [N]byte) []byte { return a[:] }(value)
+	return &ast.CallExpr{
+		Fun: &ast.FuncLit{
+			Type: &ast.FuncType{
+				Params: &ast.FieldList{List: []*ast.Field{{
+					Names: []*ast.Ident{{Name: "a"}},
+					Type: &ast.ArrayType{
+						Len: &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(slice.Len, 10)},
+						Elt: &ast.Ident{Name: "byte"},
+					},
+				}}},
+				Results: &ast.FieldList{List: []*ast.Field{{
+					Type: &ast.ArrayType{Elt: &ast.Ident{Name: "byte"}},
+				}}},
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.ReturnStmt{Results: []ast.Expr{&ast.SliceExpr{X: &ast.Ident{Name: "a"}}}},
+			}},
+		},
+		Args: []ast.Expr{value},
+	}
+}
+
+func transformValueWithHex(value ast.Expr, hex transform.Hex, deps *dependencies) ast.Expr {
+	if hex.FromString {
+		value = &ast.CallExpr{
+			Fun:  &ast.ArrayType{Elt: &ast.Ident{Name: "byte"}},
+			Args: []ast.Expr{value},
+		}
+	}
+
+	var res ast.Expr = &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   deps.pkg("encoding/hex"),
+			Sel: &ast.Ident{Name: "EncodeToString"},
+		},
+		Args: []ast.Expr{value},
+	}
+
+	if !hex.Space && hex.Prefix == "" {
+		return res
+	}
+
+	deps.addHelper(helpers.Hex)
+
+	return &ast.CallExpr{
+		Fun: &ast.Ident{Name: helpers.Hex.Name},
+		Args: []ast.Expr{
+			res,
+			&ast.Ident{Name: strconv.FormatBool(hex.Space)},
+			&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(hex.Prefix)},
+		},
+	}
+}
+
 func transformValueToCallStringMethod(value ast.Expr) ast.Expr {
 	return &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
@@ -404,6 +1546,24 @@ func transformValueToCallErrorMethod(value ast.Expr) ast.Expr {
 	}
 }
 
+func transformValueToWrappedError(value ast.Expr, deps *dependencies) ast.Expr {
+	deps.addHelper(helpers.Wrapped)
+
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: helpers.Wrapped.Name},
+		Args: []ast.Expr{value},
+	}
+}
+
+func transformValueToCallGoStringMethod(value ast.Expr) ast.Expr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   value,
+			Sel: &ast.Ident{Name: "GoString"},
+		},
+	}
+}
+
 func transformValueWithWrap(value ast.Expr, wrap transform.Wrap) ast.Expr {
 	return &ast.CallExpr{
 		Fun: &ast.Ident{Name: wrap.Wrapper},
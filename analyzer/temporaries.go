@@ -0,0 +1,337 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/edge"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/m-ocean-it/go-sprintf-bomb/analyzer/internal/transform"
+)
+
+// operandsToHoist returns the indexes of the operands that have to be moved into
+// temporaries: fmt evaluates every operand once and in order, while the rewritten
+// code evaluates them where they are printed. The methods fmt calls once every
+// operand is evaluated are called in between too. It fails when an operand with
+// side effects would not be evaluated at all.
+func operandsToHoist(typesInfo *types.Info, operands []ast.Expr, analyzed analyzedSprintfCall) ([]int, bool) {
+	type use struct {
+		index  int
+		method bool // a method of the operand is called right after it
+	}
+
+	var uses []use
+
+	for _, arg := range analyzed.args {
+		uses = append(uses, use{index: arg.directive.ArgIndex, method: callsMethod(arg.transformation)})
+
+		// The width goes after the value in the padding helpers.
+		if arg.directive.Width.FromArg {
+			uses = append(uses, use{index: arg.directive.Width.ArgIndex})
+		}
+	}
+
+	// The wrapped errors are kept after the message is built.
+	for _, w := range analyzed.wrapped {
+		uses = append(uses, use{index: w.index})
+	}
+
+	var (
+		impure []int
+		hoist  bool
+		called bool // a method was called before the current use
+		last   = -1
+	)
+
+	for i, operand := range operands {
+		if isSafeToRepeat(typesInfo, operand) {
+			continue
+		}
+
+		if !slices.ContainsFunc(uses, func(u use) bool { return u.index == i }) {
+			// A reordered format may skip operands.
+			return nil, false
+		}
+
+		impure = append(impure, i)
+	}
+
+	for _, u := range uses {
+		if !slices.Contains(impure, u.index) {
+			called = called || u.method
+			continue
+		}
+
+		if u.index <= last || called {
+			// Used twice, out of order, or after a method that may see its effects.
+			hoist = true
+		}
+
+		last = u.index
+		called = called || u.method
+	}
+
+	if !hoist {
+		return nil, true
+	}
+
+	return impure, true
+}
+
+// operandsToHoistBeforeWrites returns the indexes of the operands to move into
+// temporaries when the pieces are written one at a time: fmt evaluates every
+// operand before writing anything, so the impure operands are all hoisted as
+// soon as one of them is written after the first piece.
+func operandsToHoistBeforeWrites(
+	typesInfo *types.Info,
+	operands []ast.Expr,
+	analyzed analyzedSprintfCall,
+) ([]int, bool) {
+	if _, ok := operandsToHoist(typesInfo, operands, analyzed); !ok {
+		// An operand with side effects is skipped by a reordered format.
+		return nil, false
+	}
+
+	var impure []int
+
+	for i, operand := range operands {
+		if !isSafeToRepeat(typesInfo, operand) {
+			impure = append(impure, i)
+		}
+	}
+
+	for i, arg := range analyzed.args {
+		written := i > 0 || arg.directive.Start > 0
+		if written && slices.Contains(impure, arg.directive.ArgIndex) {
+			return impure, true
+		}
+
+		if arg.directive.Width.FromArg && slices.Contains(impure, arg.directive.Width.ArgIndex) {
+			return impure, true
+		}
+	}
+
+	return nil, true
+}
+
+// callsMethod reports whether the transformation calls String(), Error() or
+// GoString() on the operand.
+func callsMethod(t transform.Transformation) bool {
+	switch tt := t.(type) {
+	case transform.CallStringMethod, transform.CallErrorMethod, transform.WrappedError, transform.CallGoStringMethod:
+		return true
+	case transform.ToUpper:
+		return callsMethod(tt.Inner)
+	case transform.Prefix:
+		return callsMethod(tt.Inner)
+	case transform.Sign:
+		return callsMethod(tt.Inner)
+	case transform.Pad:
+		return callsMethod(tt.Inner)
+	case transform.Quote:
+		return callsMethod(tt.Inner)
+	case transform.Hex:
+		return callsMethod(tt.Inner)
+	default:
+		return false
+	}
+}
+
+// isSafeToRepeat reports whether evaluating expr several times, or not at all,
+// is cheap and can't be observed.
+func isSafeToRepeat(typesInfo *types.Info, expr ast.Expr) bool {
+	if tv, ok := typesInfo.Types[expr]; ok && tv.Value != nil {
+		return true
+	}
+
+	switch e := expr.(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return isSafeToRepeat(typesInfo, e.X)
+	case *ast.SelectorExpr:
+		if _, ok := typesInfo.Uses[e.Sel].(*types.Var); !ok {
+			return false // a method value
+		}
+
+		if sel, ok := typesInfo.Selections[e]; ok && sel.Indirect() {
+			return false // may dereference a nil pointer
+		}
+
+		return isSafeToRepeat(typesInfo, e.X)
+	default:
+		return false
+	}
+}
+
+// findStatementForTemporaries returns the statement the temporaries can be declared
+// before. The call must be evaluated exactly once every time the statement runs, and
+// nothing evaluated before the call in the statement may have side effects.
+func findStatementForTemporaries(typesInfo *types.Info, call inspector.Cursor) (inspector.Cursor, bool) {
+	for cur := call; ; cur = cur.Parent() {
+		parent := cur.Parent()
+		if parent.Node() == nil {
+			return inspector.Cursor{}, false
+		}
+
+		switch p := parent.Node().(type) {
+		case *ast.FuncLit:
+			return inspector.Cursor{}, false
+		case *ast.BinaryExpr:
+			if k, _ := cur.ParentEdge(); k == edge.BinaryExpr_Y && (p.Op == token.LAND || p.Op == token.LOR) {
+				// The call might not be evaluated at all.
+				return inspector.Cursor{}, false
+			}
+		}
+
+		stmt, ok := parent.Node().(ast.Stmt)
+		if !ok {
+			continue
+		}
+
+		switch stmt.(type) {
+		case *ast.ExprStmt, *ast.AssignStmt, *ast.ReturnStmt, *ast.DeclStmt, *ast.SendStmt:
+		default:
+			return inspector.Cursor{}, false
+		}
+
+		switch k, _ := parent.ParentEdge(); k {
+		case edge.BlockStmt_List, edge.CaseClause_Body, edge.CommClause_Body:
+		default:
+			return inspector.Cursor{}, false
+		}
+
+		if hasEffectsBefore(typesInfo, stmt, call.Node().Pos()) {
+			return inspector.Cursor{}, false
+		}
+
+		return parent, true
+	}
+}
+
+// hasEffectsBefore reports whether node evaluates function calls or receives
+// from channels before pos.
+func hasEffectsBefore(typesInfo *types.Info, node ast.Node, pos token.Pos) bool {
+	var found bool
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found || n == nil || n.Pos() >= pos {
+			return false
+		}
+
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if tv, ok := typesInfo.Types[e.Fun]; ok && tv.IsType() {
+				break // a conversion
+			}
+
+			if e.End() <= pos {
+				found = true
+			}
+		case *ast.UnaryExpr:
+			if e.Op == token.ARROW && e.End() <= pos {
+				found = true
+			}
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// newTemporaries declares a variable for each of the hoisted operands. The names
+// don't clash with anything visible from stmt or declared in its block, nor with
+// the temporaries declared in the block by other fixes.
+func newTemporaries(
+	typesInfo *types.Info,
+	stmt inspector.Cursor,
+	operands []ast.Expr,
+	hoisted []int,
+	filePkgOut *packagesFileResult,
+) ([]*ast.AssignStmt, bool) {
+	scope := enclosingScope(typesInfo, stmt)
+	if scope == nil {
+		return nil, false
+	}
+
+	pos := stmt.Node().Pos()
+
+	var res []*ast.AssignStmt
+
+	for _, i := range hoisted {
+		name := freeName("arg"+strconv.Itoa(i+1), func(name string) bool {
+			return isNameTaken(scope, pos, name, filePkgOut)
+		})
+
+		if filePkgOut.temporaries == nil {
+			filePkgOut.temporaries = map[*types.Scope][]string{}
+		}
+		filePkgOut.temporaries[scope] = append(filePkgOut.temporaries[scope], name)
+
+		res = append(res, &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: name}},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{operands[i]},
+		})
+	}
+
+	return res, true
+}
+
+// freeName returns base, or the next free numbered name: arg1_2, arg1_3, ...
+// for a base ending in a digit, strconv2, strconv3, ... for any other.
+func freeName(base string, isTaken func(name string) bool) string {
+	var sep string
+	if last := base[len(base)-1]; '0' <= last && last <= '9' {
+		sep = "_"
+	}
+
+	name := base
+	for n := 2; isTaken(name); n++ {
+		name = base + sep + strconv.Itoa(n)
+	}
+
+	return name
+}
+
+func isNameTaken(scope *types.Scope, pos token.Pos, name string, filePkgOut *packagesFileResult) bool {
+	if slices.Contains(filePkgOut.temporaries[scope], name) {
+		return true
+	}
+
+	if scope.Lookup(name) != nil {
+		return true
+	}
+
+	_, obj := scope.LookupParent(name, pos)
+
+	return obj != nil
+}
+
+// enclosingScope returns the innermost scope holding the node at cursor.
+func enclosingScope(typesInfo *types.Info, cursor inspector.Cursor) *types.Scope {
+	for cur := range cursor.Enclosing() {
+		node := cur.Node()
+
+		// The scope of a function is recorded for its type, not its body.
+		switch n := node.(type) {
+		case *ast.FuncDecl:
+			node = n.Type
+		case *ast.FuncLit:
+			node = n.Type
+		}
+
+		if scope := typesInfo.Scopes[node]; scope != nil {
+			return scope
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+func foo() {
+	enabled := true
+	_ = fmt.Sprintf("enabled=%t", enabled) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("enabled=%v", enabled) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%t", false)           // want "Sprintf could be optimized away"
+
+	f := flag(true)
+	_ = fmt.Sprintf("flag=%t", f) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("flag=%v", f) // want "Sprintf could be optimized away"
+
+	sf := stringerFlag(true)
+	_ = fmt.Sprintf("flag=%t", sf) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("flag=%v", sf) // want "Sprintf could be optimized away"
+}
+
+type flag bool
+
+type stringerFlag bool
+
+func (s stringerFlag) String() string {
+	return "String()"
+}
@@ -0,0 +1,36 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+func foo() {
+	r := 'é'
+	_ = fmt.Sprintf("char %c", r)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("[%-3c]", r)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("code %U", r)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("code %#U", r) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("[%08U]", r)   // want "Sprintf could be optimized away"
+
+	b := byte('a')
+	_ = fmt.Sprintf("%c", b) // want "Sprintf could be optimized away"
+
+	i := 65
+	_ = fmt.Sprintf("%c", i) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%U", i) // want "Sprintf could be optimized away"
+
+	u := uint64(0x1F600)
+	_ = fmt.Sprintf("%#U", u) // want "Sprintf could be optimized away"
+
+	l := letter('z')
+	_ = fmt.Sprintf("%c", l) // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%.6U", r) // precision adds digits
+	_ = fmt.Sprintf("%c", "a")
+}
+
+type letter rune
+
+func (l letter) String() string {
+	return "letter"
+} // want "Add helpers"
@@ -0,0 +1,13 @@
+package const_formats
+
+import ( // want "Fix imports"
+	"fmt"
+
+	"formats"
+)
+
+func kept(name string) formats.Format {
+	_ = fmt.Sprintf(formats.User, name) // want "Sprintf could be optimized away"
+
+	return formats.Count
+}
@@ -0,0 +1,32 @@
+package const_formats
+
+import ( // want "Fix imports"
+	"fmt"
+
+	"formats"
+)
+
+const prefix = "id="
+
+const idFormat = prefix + "%d"
+
+type greeting string
+
+const hello greeting = "hello %s"
+
+var dynamic = "%d"
+
+func constants(id int, name string) []string {
+	return []string{
+		fmt.Sprintf(idFormat, id),              // want "Sprintf could be optimized away"
+		fmt.Sprintf(prefix+"%d!", id),          // want "Sprintf could be optimized away"
+		fmt.Sprintf(string(hello), name),       // want "Sprintf could be optimized away"
+		fmt.Sprintf(formats.User, name),        // want "Sprintf could be optimized away"
+		fmt.Sprintf(string(formats.Count), id), // want "Sprintf could be optimized away"
+		fmt.Sprintf("`%s`", name),              // want "Sprintf could be optimized away"
+	}
+}
+
+func notConstant(id int) string {
+	return fmt.Sprintf(dynamic, id)
+}
@@ -0,0 +1,51 @@
+package constants
+
+import ( // want "Fix imports"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	name         = "bomb"
+	version      = 2
+	ratio        = 0.5
+	tab     rune = '\t'
+)
+
+type level int8
+
+type status string
+
+func (s status) String() string { return "status " + string(s) }
+
+func folded() []string {
+	return []string{
+		fmt.Sprintf("%s-%d", "v", 2),                       // want "Sprintf could be optimized away"
+		fmt.Sprintf("%s v%d (%.1f)", name, version, ratio), // want "Sprintf could be optimized away"
+		fmt.Sprintf("%x %08b", level(-3), uint8(5)),        // want "Sprintf could be optimized away"
+		fmt.Sprintf("%v %v %q", float32(0.1), 'a', tab),    // want "Sprintf could be optimized away"
+		fmt.Sprintf("%q %-4t|", "a\"b", true),              // want "Sprintf could be optimized away"
+		fmt.Sprintf("%*d|%[1]d", 4, 7),                     // want "Sprintf could be optimized away"
+		fmt.Sprintf("max %d", math.MaxInt8),                // want "Sprintf could be optimized away"
+		fmt.Sprintf(`%s\n`, "`x`"),                         // want "Sprintf could be optimized away"
+		fmt.Sprint("a", 1, 2),                              // want "Sprint could be optimized away"
+		fmt.Errorf("code %d", version).Error(),             // want "Errorf could be optimized away"
+	}
+}
+
+func merged(n int, s status) []string {
+	return []string{
+		fmt.Sprintf("%s-%d-%s: %d", name, version, "x", n), // want "Sprintf could be optimized away"
+		fmt.Sprintf("%d %s %s", n, "of", name),             // want "Sprintf could be optimized away"
+		fmt.Sprintf("%s %s", status("ok"), s),              // want "Sprintf could be optimized away"
+		fmt.Sprintf("%v, %T", time.Second, version),        // want "Sprintf could be optimized away"
+	}
+}
+
+func signs(u uint, x uint32) []string {
+	return []string{
+		fmt.Sprintf("%d|%+d", version, u), // want "Sprintf could be optimized away"
+		fmt.Sprintf("%s %#x", name, x),    // want "Sprintf could be optimized away"
+	}
+}
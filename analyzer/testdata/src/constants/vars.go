@@ -0,0 +1,19 @@
+package constants
+
+import "fmt"
+
+var greeting = fmt.Sprintf("hello, %s", name) // want "Sprintf could be optimized away"
+
+var Exported = fmt.Sprintf("v%d", version) // want "Sprintf could be optimized away"
+
+var typed any = fmt.Sprintf("v%d", version) // want "Sprintf could be optimized away"
+
+var (
+	first, second = fmt.Sprintf("v%d", version), "" // want "Sprintf could be optimized away"
+)
+
+var dynamic = fmt.Sprintf("%s!", greeting) // want "Sprintf could be optimized away"
+
+func use() {
+	fmt.Println(greeting, Exported, typed, first, second, dynamic)
+}
@@ -21,6 +21,10 @@ func foo() {
 	i := 2
 	_ = fmt.Sprintf("%d is int", i) // want "Sprintf could be optimized away"
 	_ = fmt.Sprintf("%d", i)        // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%d%% done", i) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%d %d", i)     // missing operand
+	_ = fmt.Sprintf("%d", i, i)     // extra operand
+	_ = fmt.Sprintf("%d %", i)      // no verb
 
 	_ = fmt.Sprintf("%s, %s, %s", "a", "b", "c") // want "Sprintf could be optimized away"
 
@@ -0,0 +1,48 @@
+package p
+
+import ( // want "Fix imports"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type row struct {
+	id    int
+	name  string
+	score float64
+	raw   []byte
+}
+
+func next() int {
+	return 42
+}
+
+func render(sb *strings.Builder, buf bytes.Buffer, r row) {
+	sb.WriteString(fmt.Sprintf("%d: %s\n", r.id, r.name))    // want "Sprintf could be optimized away"
+	buf.WriteString(fmt.Sprintf("%.2f %q", r.score, r.name)) // want "Sprintf could be optimized away"
+	buf.WriteString(fmt.Sprintf("[%s]", r.raw))              // want "Sprintf could be optimized away"
+	sb.WriteString(fmt.Sprintf("%d", next()))                // want "Sprintf could be optimized away"
+
+	if n, _ := sb.WriteString(fmt.Sprintf("%d", r.id)); n > 0 { // want "Sprintf could be optimized away"
+		return
+	}
+}
+
+func encode(buf []byte, r row, ok bool) []byte {
+	buf = append(buf, fmt.Sprintf("id=%d name=%q", r.id, r.name)...) // want "Sprintf could be optimized away"
+	buf = append(buf, fmt.Sprintf("%.3e%t", r.score, ok)...)         // want "Sprintf could be optimized away"
+	buf = append(buf, fmt.Sprintf("<%s>", r.raw)...)                 // want "Sprintf could be optimized away"
+
+	return append(buf, fmt.Sprintf("%x,%d", r.name, next())...) // want "Sprintf could be optimized away"
+}
+
+const version = 3
+
+func reuse(b []byte, r row) []byte {
+	b = append(b, fmt.Sprintf("v%d: %d", version, r.id)...) // want "Sprintf could be optimized away"
+	b = append(b, fmt.Sprintf("%d-%d", next(), next())...)  // want "Sprintf could be optimized away"
+	b = append(b, fmt.Sprintf("%d (%d)", len(b), r.id)...)  // want "Sprintf could be optimized away"
+
+	// May alias the destination, concatenated first.
+	return append(b[:0], fmt.Sprintf("<%s>", b)...) // want "Sprintf could be optimized away"
+}
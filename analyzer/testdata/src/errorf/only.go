@@ -0,0 +1,9 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+func bar(id int) error {
+	return fmt.Errorf("no record with id %d", id) // want "Errorf could be optimized away"
+}
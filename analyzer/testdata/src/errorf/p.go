@@ -0,0 +1,21 @@
+package p
+
+import ( // want "Fix imports"
+	"errors"
+	"fmt"
+)
+
+func foo(name string, code int) []error {
+	err := errors.New("timeout")
+
+	return []error{
+		fmt.Errorf("unknown user %q", name),          // want "Errorf could be optimized away"
+		fmt.Errorf("status %d: %v", code, err),       // want "Errorf could be optimized away"
+		fmt.Errorf("not implemented"),                // want "Errorf could be optimized away"
+		fmt.Errorf("100%% done"),                     // want "Errorf could be optimized away"
+		errors.New(fmt.Sprintf("bad code %d", code)), // want "Sprintf could be optimized away"
+		errors.New(fmt.Sprint("bad code ", code)),    // want "Sprint could be optimized away"
+		fmt.Errorf("user %s: %w", name, err),         // wraps err
+		fmt.Errorf("missing %s"),                     // missing operand
+	}
+}
@@ -0,0 +1,39 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+func foo() {
+	f := 3.14
+	_ = fmt.Sprintf("%e", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%E", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%f", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%F", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%g", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%G", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%x", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%X", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%b", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%v", f)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%.2f", f) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%.f", f)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%.3e", f) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%.4g", f) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%.3v", f) // want "Sprintf could be optimized away"
+
+	f32 := float32(3.14)
+	_ = fmt.Sprintf("%f", f32)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%v", f32)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%.1e", f32) // want "Sprintf could be optimized away"
+
+	wrF32 := wrappedFloat32(2.5)
+	_ = fmt.Sprintf("%g", wrF32) // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%.*f", 2, f) // precision from operand
+	_ = fmt.Sprintf("%#g", f)     // unsupported flag
+	_ = fmt.Sprintf("%.2s", "ab") // precision truncates strings
+	_ = fmt.Sprintf("%.2v", "ab") // precision truncates strings
+}
+
+type wrappedFloat32 float32
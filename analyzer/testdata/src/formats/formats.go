@@ -0,0 +1,7 @@
+package formats
+
+const User = "user %s"
+
+type Format string
+
+const Count Format = "%d items"
@@ -0,0 +1,56 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+	"math/big"
+)
+
+func foo() {
+	n := big.NewInt(42)
+	_ = fmt.Sprintf("%s", n) // *big.Int implements fmt.Formatter
+	_ = fmt.Sprintf("%d", n) // *big.Int implements fmt.Formatter
+	_ = fmt.Sprintf("%x", n) // *big.Int implements fmt.Formatter
+
+	m := money(1050)
+	_ = fmt.Sprintf("total: %v", m) // money implements fmt.Formatter
+	_ = fmt.Sprintf("total: %d", m) // money implements fmt.Formatter
+
+	id := userID(7)
+	_ = fmt.Sprintf("%#v", id)      // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("[%-#12v]", id) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%v", id)       // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#.3v", id)    // precision truncates GoString()
+
+	var err error = stackErr{}
+	_ = fmt.Sprintf("%+v", err) // the dynamic type may implement fmt.Formatter
+	_ = fmt.Sprintf("%v", err)  // want "Sprintf could be optimized away"
+}
+
+type money int64
+
+func (m money) Format(f fmt.State, verb rune) {
+	_, _ = fmt.Fprintf(f, "$%d.%02d", int64(m)/100, int64(m)%100)
+}
+
+func (m money) String() string {
+	return "money"
+}
+
+type stackErr struct{}
+
+func (e stackErr) Error() string {
+	return "failed"
+}
+
+func (e stackErr) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(e.Error()))
+	if f.Flag('+') {
+		_, _ = f.Write([]byte("\nstack trace"))
+	}
+}
+
+type userID int
+
+func (u userID) GoString() string {
+	return "userID(7)"
+} // want "Add helpers"
@@ -0,0 +1,55 @@
+package p
+
+import ( // want "Fix imports"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type header struct {
+	name  string
+	value []byte
+}
+
+func next() int {
+	return 42
+}
+
+func generate(name string, n int, ok bool, h header) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "func %s() int {\n", name) // want "Fprintf could be optimized away"
+	fmt.Fprintf(&sb, "\treturn %d\n", n)        // want "Fprintf could be optimized away"
+	fmt.Fprintf(&sb, "}")                       // want "Fprintf could be optimized away"
+
+	if ok {
+		fmt.Fprintf(&sb, "%s: %s\n", h.name, h.value) // want "Fprintf could be optimized away"
+	}
+
+	fmt.Fprintf(&sb, "%d-%d", next(), n) // want "Fprintf could be optimized away"
+
+	if _, err := fmt.Fprintf(&sb, "%d", n); err != nil { // results used
+		return ""
+	}
+
+	defer fmt.Fprintf(&sb, "%d", n) // not a plain statement
+
+	return sb.String()
+}
+
+func encode(buf *bytes.Buffer, w *bufio.Writer, id int64, size uint, ok bool, label string) {
+	fmt.Fprintf(buf, "id=%d size=%x ok=%t", id, size, ok) // want "Fprintf could be optimized away"
+	fmt.Fprintf(w, "%v %q", id, "x")                      // want "Fprintf could be optimized away"
+	fmt.Fprintf(w, "%q", label)                           // want "Fprintf could be optimized away"
+	fmt.Fprintf(w, "%d-%s", 3, "x")                       // want "Fprintf could be optimized away"
+	fmt.Fprintf(os.Stdout, "%d", id)                      // unknown writer
+	fmt.Fprintf(io.Discard, "%d", id)                     // unknown writer
+	fmt.Fprintf(newBuffer(), "%d", id)                    // the writer has side effects
+}
+
+func newBuffer() *bytes.Buffer {
+	return new(bytes.Buffer)
+}
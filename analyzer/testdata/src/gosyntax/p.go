@@ -0,0 +1,52 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+	"math/big"
+)
+
+var global = 1
+
+func foo(n *big.Int, opts map[string][]byte, cb func(...int) error) {
+	id := 42
+	_ = fmt.Sprintf("unexpected %T", id)     // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unexpected %T", 'x')    // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unexpected %T", n)      // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unexpected %T", opts)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unexpected %T", cb)     // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unexpected %T", global) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("[%-8T]", status(1))     // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%T = %v", id, id)       // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unexpected %T", error(nil))
+	_ = fmt.Sprintf("unexpected %T", struct{}{})
+	_ = fmt.Sprintf("unexpected %T", n.Sign()) // the call would be dropped
+
+	once := 1
+	_ = fmt.Sprintf("%T", once) // once would be unused
+
+	twice := 1
+	_ = fmt.Sprintf("%T", twice)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%T!", twice) // the first fix drops the other use
+
+	name := "a\tb"
+	_ = fmt.Sprintf("%#v", name) // want "Sprintf could be optimized away"
+
+	u := uint8(42)
+	_ = fmt.Sprintf("%#v", u)    // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#08v", u)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#v", id)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#v", 2.5)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#v", true) // want "Sprintf could be optimized away"
+
+	s := status(3)
+	_ = fmt.Sprintf("%#v", s) // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%#v", []int{1})
+	_ = fmt.Sprintf("%#.2v", name) // precision truncates strings
+}
+
+type status uint
+
+func (s status) String() string {
+	return "status"
+} // want "Add helpers"
@@ -0,0 +1,48 @@
+package p
+
+import ( // want "Fix imports"
+	"crypto/sha256"
+	"fmt"
+)
+
+func foo() {
+	b := []byte("payload")
+	_ = fmt.Sprintf("body: %s", b) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%x", b)       // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%X", b)       // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("% x", b)      // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%# X", b)     // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("[%-20x]", b)  // want "Sprintf could be optimized away"
+
+	sum := sha256.Sum256(b)
+	_ = fmt.Sprintf("sha256:%x", sum)              // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("sha256:%x", sha256.Sum256(b)) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("sha256:%#x", sum[:4])         // want "Sprintf could be optimized away"
+
+	s := "text"
+	_ = fmt.Sprintf("%x", s)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#x", s) // want "Sprintf could be optimized away"
+
+	var id ID
+	_ = fmt.Sprintf("%x", id)      // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%x", newID()) // want "Sprintf could be optimized away"
+
+	t := token("secret")
+	_ = fmt.Sprintf("%x", t) // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%v", b)   // fmt prints the bytes as numbers
+	_ = fmt.Sprintf("%.2x", b) // precision limits the number of bytes
+	_ = fmt.Sprintf("%s", sum) // not supported for arrays yet
+}
+
+type ID [16]byte
+
+func newID() ID {
+	return ID{1}
+}
+
+type token []byte
+
+func (t token) String() string {
+	return "***"
+} // want "Add helpers"
@@ -0,0 +1,13 @@
+package imports
+
+import ( // want "Fix imports"
+	"os"
+
+	"fmt"
+
+	"mylog"
+)
+
+func alone(name string) {
+	mylog.Info(os.Args[0], fmt.Sprintf("name %s", name)) // want "Sprintf could be optimized away"
+}
@@ -0,0 +1,14 @@
+package imports
+
+import ( // want "Fix imports"
+	// The standard library.
+	"bytes" // for the buffer
+	"fmt"
+
+	// Modules.
+	"mylog"
+)
+
+func groups(b *bytes.Buffer, n int) {
+	mylog.Info(b.Len(), fmt.Sprintf("n=%d", n)) // want "Sprintf could be optimized away"
+}
@@ -0,0 +1,9 @@
+package imports
+
+import "fmt" // want "Fix imports"
+
+var errors []error
+
+func pkgErrors(n int) {
+	errors = append(errors, fmt.Errorf("bad %d", n)) // want "Errorf could be optimized away"
+}
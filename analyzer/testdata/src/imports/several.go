@@ -0,0 +1,10 @@
+package imports
+
+import "os" // want "Fix imports"
+
+// fmt is only used by the call below.
+import "fmt"
+
+func several(n int) string {
+	return os.Getenv("HOME") + fmt.Sprintf("%d", n) // want "Sprintf could be optimized away"
+}
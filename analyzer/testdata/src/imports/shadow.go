@@ -0,0 +1,9 @@
+package imports
+
+import "fmt"
+
+func shadow(n int) string {
+	strconv := n * 2
+
+	return fmt.Sprintf("%d", strconv) // strconv is shadowed
+}
@@ -0,0 +1,7 @@
+package imports
+
+import "fmt" // want "Fix imports"
+
+func single(n int) string {
+	return fmt.Sprintf("%d items", n) // want "Sprintf could be optimized away"
+}
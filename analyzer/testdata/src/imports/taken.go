@@ -0,0 +1,11 @@
+package imports
+
+import ( // want "Fix imports"
+	"fmt"
+
+	strconv "mylog"
+)
+
+func taken(key string, n int) {
+	strconv.Info(fmt.Sprintf("%#q has %d", key, n)) // want "Sprintf could be optimized away"
+} // want "Add helpers"
@@ -0,0 +1,46 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+func foo() {
+	i := -42
+	_ = fmt.Sprintf("%x", i)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%X", i)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%o", i)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%b", i)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#x", i) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%O", i)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#d", i) // want "Sprintf could be optimized away"
+
+	u32 := uint32(0xbeef)
+	_ = fmt.Sprintf("id=%x", u32)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("id=%#x", u32) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("id=%#X", u32) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("id=%#b", u32) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("id=%O", u32)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("id=%#o", u32) // want "Sprintf could be optimized away"
+
+	i64 := int64(255)
+	_ = fmt.Sprintf("%#o", i64) // want "Sprintf could be optimized away"
+
+	bt, r := byte(1), 'a'
+	_ = fmt.Sprintf("%d %x", bt, r) // want "Sprintf could be optimized away"
+
+	m := mask(7)
+	_ = fmt.Sprintf("%b", m) // want "Sprintf could be optimized away"
+
+	sm := stringerMask(7)
+	_ = fmt.Sprintf("%o", sm)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%x", sm)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%.3x", i) // minimum number of digits
+}
+
+type mask uint8
+
+type stringerMask int
+
+func (s stringerMask) String() string {
+	return "String()"
+} // want "Add helpers"
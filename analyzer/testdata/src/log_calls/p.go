@@ -0,0 +1,39 @@
+package p
+
+import ( // want "Fix imports"
+	"log"
+	"testing"
+)
+
+type logger struct {
+	*log.Logger
+}
+
+func (l logger) Print(v ...any) {}
+
+func run(l *log.Logger, w logger, name string, code int) {
+	log.Printf("user %s", name)         // want "Printf could be optimized away"
+	log.Printf("code %d\n", code)       // want "Printf could be optimized away"
+	l.Printf("%s: %q", name, name)      // want "Printf could be optimized away"
+	w.Printf("code %d", code)           // Print is not the one of log.Logger
+	log.Fatalf("bad code %x", code)     // want "Fatalf could be optimized away"
+	log.Panicf("unknown user %s", name) // want "Panicf could be optimized away"
+	log.Printf("%d%%", code, name)      // extra operand
+}
+
+func TestSomething(t *testing.T) {
+	name := t.Name()
+
+	t.Logf("running %s...", name)   // want "Logf could be optimized away"
+	t.Logf("got %d, want %d", 1, 2) // want "Logf could be optimized away"
+	t.Errorf("%q", name)            // want "Errorf could be optimized away"
+	t.Fatalf("%5d", 42)             // want "Fatalf could be optimized away"
+	t.Fatalf("%5d", len(name))      // want "Fatalf could be optimized away"
+	t.Logf("done\n")                // Log would print an empty line
+	t.Logf("%s", name)              // the name may end with a newline
+	check(t, name)
+}
+
+func check(tb testing.TB, name string) {
+	tb.Logf("checking %s...", name) // want "Logf could be optimized away"
+} // want "Add helpers"
@@ -0,0 +1,51 @@
+package mylog
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+type Logger struct {
+	l *log.Logger
+}
+
+func Info(args ...any) { // want Info:"printWrapper"
+	log.Print(args...)
+}
+
+func Infof(format string, args ...any) { // want Infof:"printfWrapper"
+	log.Printf(format, args...)
+}
+
+func (l *Logger) Error(args ...any) { // want Error:"printWrapper"
+	l.l.Print(args...)
+}
+
+func (l *Logger) Errorf(format string, args ...any) { // want Errorf:"printfWrapper"
+	l.l.Printf(format, args...)
+}
+
+func (l *Logger) Debugf(format string, args ...any) { // want Debugf:"printfWrapper" `\(\*mylog.Logger\).Debugf is a printf wrapper$`
+	l.output(fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) output(s string) {
+	l.l.Output(3, s)
+}
+
+func Wrap(err error, args ...any) error { // want Wrap:"printWrapper"
+	return errors.Join(err, errors.New(fmt.Sprint(args...)))
+}
+
+func Wrapf(err error, format string, args ...any) error { // want Wrapf:"printfWrapper"
+	return Wrap(err, newf(format, args...))
+}
+
+func newf(format string, args ...any) string { // want newf:"printfWrapper" `mylog.newf is a printf wrapper$`
+	return fmt.Sprintf(format, args...)
+}
+
+func Warnf(format string, args ...any) {
+	Infof("WARN "+format, args...) // the format is changed
+}
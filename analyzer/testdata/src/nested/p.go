@@ -0,0 +1,15 @@
+package p
+
+import "fmt" // want "Fix imports"
+
+func label(n int) string {
+	return fmt.Sprintf("[%s]", fmt.Sprintf("%d", n)) // want "Sprintf could be optimized away"
+}
+
+func check(name string, n int) error {
+	return fmt.Errorf("bad %s: %s", name, fmt.Sprint(n)) // want "Errorf could be optimized away"
+}
+
+func encode(n int) []byte {
+	return append([]byte(fmt.Sprint(n)), fmt.Sprintf("<%d>", n)...) // want "Sprint could be optimized away" "Sprintf could be optimized away"
+}
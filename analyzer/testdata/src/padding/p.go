@@ -0,0 +1,36 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+func foo() {
+	name := "John"
+	n := 42
+	_ = fmt.Sprintf("%-20s %8d", name, n) // want "Sprintf could be optimized away"
+
+	h, m := 9, 5
+	_ = fmt.Sprintf("%02d:%02d", h, m) // want "Sprintf could be optimized away"
+
+	f := 3.14159
+	_ = fmt.Sprintf("%05.2f", f) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%+.1f", f)  // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%+d", n) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("% d", n) // want "Sprintf could be optimized away"
+
+	u := uint(7)
+	_ = fmt.Sprintf("%+d", u)     // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%#08x", u)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%05s", "a")  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%05s", name) // want "Sprintf could be optimized away"
+
+	ok := true
+	_ = fmt.Sprintf("[%6t]", ok) // want "Sprintf could be optimized away"
+
+	w := 10
+	_ = fmt.Sprintf("%*s|", w, name) // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%-*s|", w, name) // negative widths would need special care
+	_ = fmt.Sprintf("%*s|", u, name)  // width is not an int
+} // want "Add helpers"
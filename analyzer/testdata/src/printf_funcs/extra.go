@@ -0,0 +1,9 @@
+package p
+
+func infof(format string, args ...any) { // want infof:"printfWrapper" `printf_funcs.infof is a printf wrapper, its calls could be rewritten with -printf-funcs=printf_funcs.infof=info`
+	debugf(format, args...)
+}
+
+func info(args ...any) { // want info:"printWrapper"
+	debug(args...)
+}
@@ -0,0 +1,44 @@
+package p
+
+import ( // want "Fix imports"
+	"errors"
+
+	"mylog"
+)
+
+func logf(format string, args ...any) { // want logf:"printfWrapper" `printf_funcs.logf is a printf wrapper$`
+	mylog.Infof(format, args...)
+}
+
+func debugf(format string, args ...any) { // want debugf:"printfWrapper"
+	logf(format, args...)
+}
+
+func debug(args ...any) { // want debug:"printWrapper"
+	mylog.Info(args...)
+}
+
+func newLogger() *mylog.Logger {
+	return &mylog.Logger{}
+}
+
+func run(l *mylog.Logger, name string, code int) error {
+	mylog.Infof("user %s", name)                           // want "Infof could be optimized away"
+	l.Errorf("code %d for %q", code, name)                 // want "Errorf could be optimized away"
+	newLogger().Errorf("code %d", code)                    // want "Errorf could be optimized away"
+	l.Debugf("user %s", name)                              // not mapped
+	logf("code %d", code)                                  // not mapped
+	mylog.Infof("code %d", code, name)                     // extra operand
+	debugf("%[1]s=%[1]q", newName())                       // want "debugf could be optimized away"
+	err := mylog.Wrapf(errors.New("eof"), "open %s", name) // want "Wrapf could be optimized away"
+
+	return mylog.Wrapf(err, "code %[1]d, %[1]x", newCode()) // want "Wrapf could be optimized away"
+}
+
+func newName() string {
+	return "name"
+}
+
+func newCode() int {
+	return 42
+}
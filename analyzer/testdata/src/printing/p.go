@@ -0,0 +1,52 @@
+package printing
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+type point struct{ x, y int }
+
+func (p point) String() string {
+	return "point"
+}
+
+func sum(ns ...int) int {
+	total := 0
+	for _, n := range ns {
+		total += n
+	}
+
+	return total
+}
+
+func parens(n int, name string, p *point) {
+	_ = fmt.Sprintf("n=%d", n)[1:]          // want "Sprintf could be optimized away"
+	_ = len(fmt.Sprintf("n=%d", n)) * 2     // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%s", *p)               // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%s", p) + "!"          // want "Sprintf could be optimized away"
+	_ = "<" + fmt.Sprintf("%s>", name)      // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%s>", name) < "<"      // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%d", n+1) + name[n-1:] // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%s", name+name) + name // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%s: %d", name, -n)[0]  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%q", name)[:2]         // want "Sprintf could be optimized away"
+}
+
+func comments(items []int) {
+	_ = fmt.Sprintf("%d items", len(items /* all of them */)) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("total %d", sum(                          // want "Sprintf could be optimized away"
+		1, // one
+		2,
+	))
+	_ = fmt.Sprintf("%d of %d", len(items) /* done */, cap(items)) // the comment would be lost
+	_ = fmt.Sprintf("%d items",                                    // the comment would be lost
+		len(items))
+}
+
+func long(name string, n int) string {
+	if n > 0 {
+		return fmt.Sprintf("first %s, second %s, third %s, fourth %s, fifth %d", name, name, name, name, n) // want "Sprintf could be optimized away"
+	}
+
+	return fmt.Sprintf("%s", name) // want "Sprintf could be optimized away"
+}
@@ -0,0 +1,42 @@
+package p
+
+import ( // want "Fix imports"
+	"errors"
+	"fmt"
+)
+
+func foo() {
+	key := "user name"
+	_ = fmt.Sprintf("unknown key %q", key)   // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unknown key %+q", key)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unknown key %#q", key)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("unknown key %#+q", key) // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("[%-12q]", key)          // want "Sprintf could be optimized away"
+
+	b := []byte("raw")
+	_ = fmt.Sprintf("%q", b) // want "Sprintf could be optimized away"
+
+	r := 'é'
+	_ = fmt.Sprintf("%q", r)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%+q", r) // want "Sprintf could be optimized away"
+
+	c := byte('a')
+	_ = fmt.Sprintf("%q", c) // want "Sprintf could be optimized away"
+
+	i := 65
+	_ = fmt.Sprintf("%q", i) // want "Sprintf could be optimized away"
+
+	err := errors.New("not found")
+	_ = fmt.Sprintf("%q", err) // want "Sprintf could be optimized away"
+
+	s := status(1)
+	_ = fmt.Sprintf("%q", s) // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%.3q", key) // precision truncates strings
+}
+
+type status int
+
+func (s status) String() string {
+	return "active"
+} // want "Add helpers"
@@ -0,0 +1,27 @@
+package raw_strings
+
+import ( // want "Fix imports"
+	"fmt"
+	"strings"
+)
+
+func query(table string, id int) string {
+	return /* want "Sprintf could be optimized away" */ fmt.Sprintf(`SELECT name
+FROM %s
+WHERE id = %d`, table, id)
+}
+
+func usage(name string) error {
+	return /* want "Errorf could be optimized away" */ fmt.Errorf(`usage: %s [flags]
+
+Flags are "quoted" here.
+`, name)
+}
+
+func tag(sb *strings.Builder, class string) {
+	fmt.Fprintf(sb, `<p class="%s">`, class) // want "Fprintf could be optimized away"
+}
+
+func percent(n int) []byte {
+	return append([]byte(`\done `), fmt.Sprintf(`%d%%`, n)...) // want "Sprintf could be optimized away"
+}
@@ -0,0 +1,56 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+)
+
+type config struct {
+	name string
+}
+
+type counter struct {
+	n int
+}
+
+func (c *counter) Inc() int {
+	c.n++
+	return c.n
+}
+
+func (c *counter) String() string {
+	return string(rune('0' + c.n))
+}
+
+func load() string {
+	return "value"
+}
+
+func foo(cfg config, pcfg *config) string {
+	key := "timeout"
+	_ = fmt.Sprintf("%[1]s=%[1]q", key)              // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%[2]s %[1]s", "world", "hello") // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%s/%[1]s", cfg.name)            // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("%[2]d", load(), 7)              // load() would not be called
+
+	v := fmt.Sprintf("%[1]s=%[1]q", load()) // want "Sprintf could be optimized away"
+	w := fmt.Sprintf("%[1]s%[1]s", load())  // want "Sprintf could be optimized away"
+	fmt.Println(v, w)
+
+	if key != "" {
+		return fmt.Sprintf("%[2]s: %[1]s", load(), pcfg.name) // want "Sprintf could be optimized away"
+	}
+
+	if fmt.Sprintf("%[1]s%[1]s", load()) == "" { // no place for a temporary
+		return ""
+	}
+
+	_ = key == "" || fmt.Sprintf("%[1]s%[1]s", load()) == "" // may not be evaluated
+
+	_ = fmt.Sprintf("%[3]s", key) // bad index
+
+	return ""
+}
+
+func methods(c *counter) string {
+	return fmt.Sprintf("%v %d", c, c.Inc()) // want "Sprintf could be optimized away"
+}
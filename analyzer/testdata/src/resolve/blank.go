@@ -0,0 +1,5 @@
+package p
+
+import (
+	_ "fmt"
+)
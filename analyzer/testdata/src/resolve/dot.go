@@ -0,0 +1,13 @@
+package p
+
+import ( // want "Fix imports"
+	. "fmt"
+)
+
+var _ Stringer = id(0)
+
+type id int
+
+func (i id) String() string {
+	return Sprint(int(i)) // want "Sprint could be optimized away"
+}
@@ -0,0 +1,9 @@
+package p
+
+import ( // want "Fix imports"
+	f "fmt"
+)
+
+func alias(name string, n int) string {
+	return f.Sprintf("%s=%d", name, n) // want "Sprintf could be optimized away"
+}
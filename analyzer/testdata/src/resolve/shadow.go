@@ -0,0 +1,17 @@
+package p
+
+import (
+	"fmt"
+)
+
+type printer struct{}
+
+func (printer) Sprintf(format string, args ...any) string {
+	return format
+}
+
+func shadowed(s fmt.Stringer) string {
+	fmt := printer{}
+
+	return fmt.Sprintf("%s", s) // not the fmt package
+}
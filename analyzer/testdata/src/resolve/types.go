@@ -0,0 +1,10 @@
+package p
+
+import ( // want "Fix imports"
+	"fmt"
+	_ "fmt"
+)
+
+func typeOnly(s fmt.Stringer, n int) string {
+	return s.String() + fmt.Sprintf("%d", n) // want "Sprintf could be optimized away"
+}
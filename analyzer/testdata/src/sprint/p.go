@@ -0,0 +1,26 @@
+package p
+
+import ( // want "Fix imports"
+	"errors"
+	"fmt"
+)
+
+func foo() {
+	name, n, f := "apples", 3, 1.5
+	err := errors.New("not found")
+
+	_ = fmt.Sprint(n)                  // want "Sprint could be optimized away"
+	_ = fmt.Sprint(n, f)               // want "Sprint could be optimized away"
+	_ = fmt.Sprint(n, name, f)         // want "Sprint could be optimized away"
+	_ = fmt.Sprint("count: ", n)       // want "Sprint could be optimized away"
+	_ = fmt.Sprint("failed: ", err)    // want "Sprint could be optimized away"
+	_ = fmt.Sprint(label("x"), n)      // want "Sprint could be optimized away"
+	_ = fmt.Sprintln(n)                // want "Sprintln could be optimized away"
+	_ = fmt.Sprintln(name, n, f, true) // want "Sprintln could be optimized away"
+
+	_ = fmt.Sprint(n, err) // err may hold a string
+	_ = fmt.Sprint([]int{n})
+	_ = fmt.Sprint()
+}
+
+type label string
@@ -0,0 +1,71 @@
+package p
+
+import ( // want "Fix imports"
+	"errors"
+	"fmt"
+)
+
+func foo() {
+	s := "John"
+	_ = fmt.Sprintf("name: %v", s)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("name: %+v", s) // want "Sprintf could be optimized away"
+
+	i := 2
+	_ = fmt.Sprintf("%v apples", i) // want "Sprintf could be optimized away"
+
+	i64 := int64(2)
+	_ = fmt.Sprintf("%v apples", i64) // want "Sprintf could be optimized away"
+
+	u8 := uint8(150)
+	_ = fmt.Sprintf("%v apples", u8) // want "Sprintf could be optimized away"
+
+	f := 3.14
+	_ = fmt.Sprintf("pi is %v", f) // want "Sprintf could be optimized away"
+
+	err := errors.New("some error")
+	_ = fmt.Sprintf("failed: %v", err)  // want "Sprintf could be optimized away"
+	_ = fmt.Sprintf("failed: %+v", err) // the dynamic type may implement fmt.Formatter
+
+	cs := customStringer{}
+	_ = fmt.Sprintf("This is %v", cs) // want "Sprintf could be optimized away"
+
+	strerError := stringerError("hello world")
+	_ = fmt.Sprintf("%v", strerError) // want "Sprintf could be optimized away"
+
+	strInt := stringerInt(1)
+	_ = fmt.Sprintf("%v", strInt) // want "Sprintf could be optimized away"
+
+	wrStr := wrappedString("hello world")
+	_ = fmt.Sprintf("wrapped string: %v", wrStr) // want "Sprintf could be optimized away"
+
+	wrInt := wrappedInt(-2)
+	_ = fmt.Sprintf("wrapped int: %v!", wrInt) // want "Sprintf could be optimized away"
+
+	_ = fmt.Sprintf("%v", []int{1})          // unsupported type
+	_ = fmt.Sprintf("%v", struct{ A int }{}) // unsupported type
+}
+
+type customStringer struct{}
+
+func (c customStringer) String() string {
+	return "Hello from custom stringer!"
+}
+
+type stringerError string
+
+func (s stringerError) String() string {
+	return "String()"
+}
+func (s stringerError) Error() string {
+	return "Error()"
+}
+
+type stringerInt int
+
+func (s stringerInt) String() string {
+	return "String()"
+}
+
+type wrappedString string
+
+type wrappedInt int
@@ -0,0 +1,50 @@
+package p
+
+import ( // want "Fix imports"
+	"errors"
+	"fmt"
+	"os"
+)
+
+type codeError int
+
+func (e codeError) Error() string {
+	return "code " + fmt.Sprint(int(e)) // want "Sprint could be optimized away"
+}
+
+type pathError struct{ path string }
+
+func (e *pathError) Error() string {
+	return "bad path " + e.path
+}
+
+func open(name string) error {
+	return os.ErrNotExist
+}
+
+func foo(name string, err error, code codeError) []error {
+	return []error{
+		fmt.Errorf("user %s: %w", name, err),         // want "Errorf could be optimized away"
+		fmt.Errorf("%w", code),                       // want "Errorf could be optimized away"
+		fmt.Errorf("%w; %w", err, code),              // want "Errorf could be optimized away"
+		fmt.Errorf("%[2]w: %[1]w, %[2]v", err, code), // want "Errorf could be optimized away"
+		fmt.Errorf("status %d", 42),                  // want "Errorf could be optimized away"
+		fmt.Errorf("%+w", err),                       // flags
+		fmt.Errorf("%w", name),                       // not an error
+		fmt.Errorf("%w", errors.New("a")),            // evaluated twice, no statement to hoist into
+	}
+}
+
+func nilErrors(path *pathError) []error {
+	var err error
+
+	return []error{
+		fmt.Errorf("ctx: %w", err),     // want "Errorf could be optimized away"
+		fmt.Errorf("%w, %w", err, err), // want "Errorf could be optimized away"
+		fmt.Errorf("ctx: %w", path),    // may be a nil pointer
+	}
+}
+
+func bar(name string) error {
+	return fmt.Errorf("open %s: %w", name, open(name)) // want "Errorf could be optimized away"
+} // want "Add helpers"
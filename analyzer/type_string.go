@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// reflectTypeString returns the name of t the way reflect.Type.String does,
+// which is what fmt prints for %T. It fails for the types reflect spells
+// differently from go/types, e.g. struct and interface literals or generics.
+func reflectTypeString(t types.Type) (string, bool) {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsUntyped != 0:
+			return "", false
+		case t.Kind() == types.UnsafePointer:
+			return "unsafe.Pointer", true
+		default:
+			// reflect knows nothing about byte and rune.
+			return types.Typ[t.Kind()].Name(), true
+		}
+	case *types.Named:
+		if t.TypeArgs().Len() > 0 {
+			return "", false
+		}
+
+		if t.Obj().Pkg() == nil {
+			return t.Obj().Name(), true
+		}
+
+		// reflect qualifies the name with the package name, not the path.
+		return t.Obj().Pkg().Name() + "." + t.Obj().Name(), true
+	case *types.Pointer:
+		return prefixTypeString("*", t.Elem())
+	case *types.Slice:
+		return prefixTypeString("[]", t.Elem())
+	case *types.Array:
+		return prefixTypeString("["+strconv.FormatInt(t.Len(), 10)+"]", t.Elem())
+	case *types.Map:
+		key, ok := reflectTypeString(t.Key())
+		if !ok {
+			return "", false
+		}
+
+		return prefixTypeString("map["+key+"]", t.Elem())
+	case *types.Chan:
+		switch t.Dir() {
+		case types.SendOnly:
+			return prefixTypeString("chan<- ", t.Elem())
+		case types.RecvOnly:
+			return prefixTypeString("<-chan ", t.Elem())
+		}
+
+		if elem, ok := t.Elem().(*types.Chan); ok && elem.Dir() == types.RecvOnly {
+			// chan (<-chan int)
+			elemString, ok := reflectTypeString(elem)
+			return "chan (" + elemString + ")", ok
+		}
+
+		return prefixTypeString("chan ", t.Elem())
+	case *types.Signature:
+		return signatureTypeString(t)
+	default:
+		return "", false
+	}
+}
+
+func prefixTypeString(prefix string, elem types.Type) (string, bool) {
+	elemString, ok := reflectTypeString(elem)
+	if !ok {
+		return "", false
+	}
+
+	return prefix + elemString, true
+}
+
+func signatureTypeString(sig *types.Signature) (string, bool) {
+	var sb strings.Builder
+
+	sb.WriteString("func(")
+
+	for i := range sig.Params().Len() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		param := sig.Params().At(i).Type()
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			sb.WriteString("...")
+			param = param.(*types.Slice).Elem()
+		}
+
+		paramString, ok := reflectTypeString(param)
+		if !ok {
+			return "", false
+		}
+
+		sb.WriteString(paramString)
+	}
+
+	sb.WriteString(")")
+
+	results := sig.Results()
+
+	if results.Len() > 0 {
+		sb.WriteString(" ")
+	}
+	if results.Len() > 1 {
+		sb.WriteString("(")
+	}
+
+	for i := range results.Len() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		resultString, ok := reflectTypeString(results.At(i).Type())
+		if !ok {
+			return "", false
+		}
+
+		sb.WriteString(resultString)
+	}
+
+	if results.Len() > 1 {
+		sb.WriteString(")")
+	}
+
+	return sb.String(), true
+}
+
+// canDropOperands reports whether the operands left out by the rewrite may
+// disappear from the code.
+func (a analyzedSprintfCall) canDropOperands(typesInfo *types.Info, varUses map[*types.Var]int) bool {
+	for _, index := range a.droppedOperands() {
+		if !canDropOperand(typesInfo, a.operands[index], varUses) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// canDropOperand reports whether the operand may disappear from the code: it
+// must be free of side effects, and a local variable other than a parameter
+// must be used elsewhere so that the compiler doesn't complain about it.
+func canDropOperand(typesInfo *types.Info, expr ast.Expr, varUses map[*types.Var]int) bool {
+	if tv, ok := typesInfo.Types[expr]; ok && tv.Value != nil {
+		return true
+	}
+
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	v, ok := typesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return false
+	}
+
+	if v.Kind() != types.LocalVar {
+		// Unused parameters are fine.
+		return true
+	}
+
+	return varUses[v] > 1
+}
+
+// countVarUses counts the uses of every local variable of the package, once
+// for all the calls.
+func countVarUses(typesInfo *types.Info) map[*types.Var]int {
+	uses := map[*types.Var]int{}
+
+	for _, obj := range typesInfo.Uses {
+		if v, ok := obj.(*types.Var); ok && v.Kind() == types.LocalVar {
+			uses[v]++
+		}
+	}
+
+	return uses
+}
@@ -0,0 +1,226 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+type wrapperKind int
+
+const (
+	// printfKind forwards a format and its operands, like fmt.Sprintf.
+	printfKind wrapperKind = iota + 1
+	// printKind forwards its operands only, like fmt.Sprint.
+	printKind
+)
+
+// printWrapper is the fact exported for the functions that forward their
+// operands to fmt or to another wrapper, so that the wrappers of wrappers
+// are found in the packages importing them too.
+type printWrapper struct {
+	Kind wrapperKind
+}
+
+func (*printWrapper) AFact() {}
+
+func (w *printWrapper) String() string {
+	if w.Kind == printfKind {
+		return "printfWrapper"
+	}
+
+	return "printWrapper"
+}
+
+// knownPrintFuncs are the functions of the standard library that format their
+// operands like fmt.Sprintf or fmt.Sprint. Sprintln and friends separate the
+// operands differently, and are left out.
+var knownPrintFuncs = map[string]wrapperKind{
+	"fmt.Appendf":          printfKind,
+	"fmt.Errorf":           printfKind,
+	"fmt.Fprintf":          printfKind,
+	"fmt.Printf":           printfKind,
+	"fmt.Sprintf":          printfKind,
+	"log.Fatalf":           printfKind,
+	"log.Panicf":           printfKind,
+	"log.Printf":           printfKind,
+	"(*log.Logger).Fatalf": printfKind,
+	"(*log.Logger).Panicf": printfKind,
+	"(*log.Logger).Printf": printfKind,
+	"fmt.Append":           printKind,
+	"fmt.Fprint":           printKind,
+	"fmt.Print":            printKind,
+	"fmt.Sprint":           printKind,
+	"log.Fatal":            printKind,
+	"log.Panic":            printKind,
+	"log.Print":            printKind,
+	"(*log.Logger).Fatal":  printKind,
+	"(*log.Logger).Panic":  printKind,
+	"(*log.Logger).Print":  printKind,
+}
+
+// findWrappers exports a fact for every function of the package that forwards
+// its format and operands to a printf-like function, the way vet's printf pass
+// does, and reports the printf wrappers that are not mapped to a sibling yet.
+func findWrappers(pass *analysis.Pass, cfg *config) {
+	var decls []*ast.FuncDecl
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Body != nil {
+				decls = append(decls, fd)
+			}
+		}
+	}
+
+	found := map[*types.Func]wrapperKind{}
+
+	// A wrapper may call a wrapper declared further down.
+	for changed := true; changed; {
+		changed = false
+
+		for _, fd := range decls {
+			fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok || found[fn] != 0 {
+				continue
+			}
+
+			if kind := wrapperKindOf(pass, fd, fn, found); kind != 0 {
+				found[fn] = kind
+				changed = true
+
+				pass.ExportObjectFact(fn, &printWrapper{Kind: kind})
+			}
+		}
+	}
+
+	for _, fd := range decls {
+		fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+		if !ok || found[fn] != printfKind {
+			continue
+		}
+
+		if _, ok := cfg.printfFuncs[fn.FullName()]; ok {
+			continue
+		}
+
+		message := fn.FullName() + " is a printf wrapper"
+		if sibling, ok := printSibling(fn, found); ok {
+			message += ", its calls could be rewritten with -printf-funcs=" + fn.FullName() + "=" + sibling
+		}
+
+		pass.Report(analysis.Diagnostic{
+			Pos:     fd.Name.Pos(),
+			End:     fd.Name.End(),
+			Message: message,
+		})
+	}
+}
+
+// wrapperKindOf returns the kind of wrapper fn is, or 0. Its last parameters
+// must be a format string, for printf wrappers, and the variadic operands,
+// which are passed on unchanged.
+func wrapperKindOf(pass *analysis.Pass, fd *ast.FuncDecl, fn *types.Func, found map[*types.Func]wrapperKind) wrapperKind {
+	sig := fn.Signature()
+
+	n := sig.Params().Len()
+	if !sig.Variadic() || n == 0 {
+		return 0
+	}
+
+	args := sig.Params().At(n - 1)
+	if elem := args.Type().(*types.Slice).Elem(); !types.IsInterface(elem) || !elem.Underlying().(*types.Interface).Empty() {
+		return 0
+	}
+
+	var format *types.Var
+	if _, ok := printfFormatIndex(fn); ok {
+		format = sig.Params().At(n - 2)
+	}
+
+	var kind wrapperKind
+
+	ast.Inspect(fd.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || kind != 0 {
+			return kind == 0
+		}
+
+		if !call.Ellipsis.IsValid() || len(call.Args) == 0 {
+			return true
+		}
+
+		if last, ok := ast.Unparen(call.Args[len(call.Args)-1]).(*ast.Ident); !ok || pass.TypesInfo.Uses[last] != args {
+			return true
+		}
+
+		calleeKind := calleeWrapperKind(pass, call, found)
+
+		switch calleeKind {
+		case printfKind:
+			if format == nil || len(call.Args) < 2 {
+				return true
+			}
+
+			if prev, ok := ast.Unparen(call.Args[len(call.Args)-2]).(*ast.Ident); ok && pass.TypesInfo.Uses[prev] == format {
+				kind = printfKind
+			}
+		case printKind:
+			kind = printKind
+		}
+
+		return kind == 0
+	})
+
+	return kind
+}
+
+func calleeWrapperKind(pass *analysis.Pass, call *ast.CallExpr, found map[*types.Func]wrapperKind) wrapperKind {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return 0
+	}
+
+	if kind, ok := knownPrintFuncs[fn.FullName()]; ok {
+		return kind
+	}
+
+	if kind, ok := found[fn]; ok {
+		return kind
+	}
+
+	var fact printWrapper
+	if fn.Pkg() != pass.Pkg && pass.ImportObjectFact(fn, &fact) {
+		return fact.Kind
+	}
+
+	return 0
+}
+
+// printSibling returns the name of the print wrapper declared next to the printf
+// wrapper fn, e.g. Info for Infof.
+func printSibling(fn *types.Func, found map[*types.Func]wrapperKind) (string, bool) {
+	name := fn.Name()
+	if len(name) < 2 || name[len(name)-1] != 'f' {
+		return "", false
+	}
+
+	name = name[:len(name)-1]
+
+	var obj types.Object
+
+	if recv := fn.Signature().Recv(); recv != nil {
+		obj, _, _ = types.LookupFieldOrMethod(recv.Type(), true, fn.Pkg(), name)
+	} else {
+		obj = fn.Pkg().Scope().Lookup(name)
+	}
+
+	sibling, ok := obj.(*types.Func)
+	if !ok || found[sibling] != printKind {
+		return "", false
+	}
+
+	return name, true
+}